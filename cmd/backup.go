@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"habit-tracker-api/internal/config"
+	"habit-tracker-api/internal/repository"
+)
+
+// Имена файлов копий: habit_tracker-20060102T150405Z.db — сортируются по времени
+const (
+	backupPrefix = "habit_tracker-"
+	backupSuffix = ".db"
+)
+
+// runBackup — резервные копии BoltDB с ротацией:
+//
+//	habit-tracker backup -dir backups -keep 7 -every 6h
+//
+// Без -url копия снимается прямо с файла базы, поэтому сервер должен быть остановлен
+// (BoltDB держит эксклюзивную блокировку). С -url копия скачивается с работающего
+// сервера через /admin/backup от имени администратора -email; пароль — в BACKUP_PASSWORD.
+func runBackup(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	dbPath := fs.String("db", cfg.DBPath, "BoltDB file to back up")
+	dir := fs.String("dir", "backups", "directory for backup files")
+	keep := fs.Int("keep", 7, "number of newest backups to keep (0 keeps all)")
+	every := fs.Duration("every", 0, "repeat with this interval (0 runs once)")
+	url := fs.String("url", "", "base URL of a running server, e.g. http://localhost:8080")
+	email := fs.String("email", "", "admin email for -url (password in BACKUP_PASSWORD)")
+	fs.Parse(args)
+
+	if *keep < 0 {
+		return errors.New("-keep must not be negative")
+	}
+	source := func(w io.Writer) error { return snapshotFile(*dbPath, w) }
+	if *url != "" {
+		if *email == "" || os.Getenv("BACKUP_PASSWORD") == "" {
+			return errors.New("-url requires -email and BACKUP_PASSWORD")
+		}
+		source = func(w io.Writer) error {
+			return downloadSnapshot(strings.TrimRight(*url, "/"), *email, os.Getenv("BACKUP_PASSWORD"), w)
+		}
+	}
+	if err := os.MkdirAll(*dir, 0700); err != nil {
+		return err
+	}
+
+	for {
+		path, err := writeBackup(*dir, source)
+		if err == nil {
+			log.Printf("backup written: %s", path)
+			err = pruneBackups(*dir, *keep)
+		}
+		if *every == 0 {
+			return err
+		}
+		// в периодическом режиме ошибка одной попытки не останавливает следующие
+		if err != nil {
+			log.Printf("backup failed: %v", err)
+		}
+		time.Sleep(*every)
+	}
+}
+
+// writeBackup пишет копию во временный файл, проверяет её и только потом
+// даёт ей окончательное имя — неполных копий в каталоге не бывает
+func writeBackup(dir string, source func(w io.Writer) error) (string, error) {
+	name := backupPrefix + time.Now().UTC().Format("20060102T150405Z") + backupSuffix
+	path := filepath.Join(dir, name)
+	tmp := path + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	err = source(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = repository.CheckSnapshot(tmp)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+// pruneBackups оставляет keep самых новых копий
+func pruneBackups(dir string, keep int) error {
+	if keep == 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), backupPrefix) && strings.HasSuffix(e.Name(), backupSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return err
+		}
+		log.Printf("backup removed: %s", names[0])
+		names = names[1:]
+	}
+	return nil
+}
+
+// snapshotFile снимает копию с файла базы, открывая его только на чтение
+func snapshotFile(path string, w io.Writer) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: 1 * time.Second})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return fmt.Errorf("%s is locked: stop the server or use -url", path)
+	}
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	_, err = repository.NewSnapshot(db).WriteTo(w)
+	return err
+}
+
+// downloadSnapshot входит как администратор и скачивает копию с /admin/backup.
+// Токен берётся заново на каждую копию: access-токены живут недолго.
+func downloadSnapshot(baseURL, email, password string, w io.Writer) error {
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	resp, err := http.Post(baseURL+"/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("login: %s", resp.Status)
+	}
+	var tokens struct {
+		AccessToken string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return fmt.Errorf("login: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/admin/backup", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	snap, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer snap.Body.Close()
+	if snap.StatusCode != http.StatusOK {
+		return fmt.Errorf("download: %s", snap.Status)
+	}
+	_, err = io.Copy(w, snap.Body)
+	return err
+}
+
+// runRestore — восстановление базы из копии:
+//
+//	habit-tracker restore backups/habit_tracker-20260101T000000Z.db
+//
+// Копия сначала проверяется; текущая база сохраняется рядом
+// с суффиксом .before-restore-<время>. Сервер должен быть остановлен.
+func runRestore(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	dbPath := fs.String("db", cfg.DBPath, "BoltDB file to replace")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("usage: restore [-db path] <snapshot file>")
+	}
+	snapshot := fs.Arg(0)
+
+	if err := repository.CheckSnapshot(snapshot); err != nil {
+		return err
+	}
+	// Убеждаемся, что базу никто не держит открытой
+	if _, err := os.Stat(*dbPath); err == nil {
+		db, err := bolt.Open(*dbPath, 0600, &bolt.Options{Timeout: 1 * time.Second})
+		if errors.Is(err, bolt.ErrTimeout) {
+			return fmt.Errorf("%s is in use: stop the server first", *dbPath)
+		}
+		if err != nil {
+			return err
+		}
+		db.Close()
+	}
+
+	// Копируем рядом с базой, чтобы подмена была атомарным rename
+	tmp := *dbPath + ".restore"
+	if err := copyFile(snapshot, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if _, err := os.Stat(*dbPath); err == nil {
+		old := *dbPath + ".before-restore-" + time.Now().UTC().Format("20060102T150405Z")
+		if err := os.Rename(*dbPath, old); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		log.Printf("previous database saved as %s", old)
+	}
+	if err := os.Rename(tmp, *dbPath); err != nil {
+		return err
+	}
+	log.Printf("restored %s from %s", *dbPath, snapshot)
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
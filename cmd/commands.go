@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"habit-tracker-api/internal/config"
+	"habit-tracker-api/internal/domain"
+	"habit-tracker-api/internal/transfer"
+)
+
+// runCommand выполняет служебную подкоманду вместо запуска сервера
+func runCommand(cfg *config.Config, name string, args []string) error {
+	switch name {
+	case "migrate":
+		return runMigrate(cfg, args)
+	case "backup":
+		return runBackup(cfg, args)
+	case "restore":
+		return runRestore(cfg, args)
+	case "purge-orphans":
+		return runPurgeOrphans(cfg, args)
+	}
+	return fmt.Errorf("unknown command %q (available: migrate, backup, restore, purge-orphans)", name)
+}
+
+// runMigrate — перенос данных между хранилищами:
+//
+//	habit-tracker migrate -from bolt:habit_tracker.db -to sqlite:habit_tracker.sqlite
+//
+// Сервер при этом должен быть остановлен: BoltDB открывается эксклюзивно.
+// Прерванный перенос продолжается с того же места при повторном запуске.
+func runMigrate(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	from := fs.String("from", cfg.Storage+":"+cfg.StoragePath(), "source backend as kind:path (bolt, sqlite)")
+	to := fs.String("to", "", "destination backend as kind:path (bolt, sqlite)")
+	statePath := fs.String("state", "", "progress file for resuming (default: <to path>.migrate-state)")
+	fs.Parse(args)
+
+	srcKind, srcPath, err := parseBackend(*from)
+	if err != nil {
+		return fmt.Errorf("-from: %w", err)
+	}
+	dstKind, dstPath, err := parseBackend(*to)
+	if err != nil {
+		return fmt.Errorf("-to: %w", err)
+	}
+	if srcPath == dstPath {
+		return fmt.Errorf("source and destination are the same file")
+	}
+	if *statePath == "" {
+		*statePath = dstPath + ".migrate-state"
+	}
+
+	src, closeSrc, err := openBackend(srcKind, srcPath)
+	if err != nil {
+		return err
+	}
+	defer closeSrc()
+	dst, closeDst, err := openBackend(dstKind, dstPath)
+	if err != nil {
+		return err
+	}
+	defer closeDst()
+
+	log.Printf("migrating %s -> %s (state: %s)", *from, *to, *statePath)
+	counts, err := transfer.Run(src, dst, *statePath, log.Printf)
+	if err != nil {
+		return err
+	}
+	log.Printf("done: %d users, %d habits, %d checkins; checksums match", counts.Users, counts.Habits, counts.Checkins)
+	return nil
+}
+
+// runPurgeOrphans удаляет отметки, чья привычка уже удалена
+// (остались от версий без каскадного удаления):
+//
+//	habit-tracker purge-orphans [-dry-run]
+//
+// Работает с хранилищем из конфигурации; сервер должен быть остановлен.
+func runPurgeOrphans(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("purge-orphans", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only report orphaned check-ins")
+	fs.Parse(args)
+
+	stores, closeStores, err := openStores(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStores()
+
+	habits := map[string]bool{}
+	err = stores.Habits.ForEach(func(h *domain.Habit) error {
+		habits[h.ID] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Удаляем после обхода: менять хранилище во время ForEach нельзя
+	var orphans []domain.HabitCheckin
+	err = stores.Checkins.ForEach(func(hc *domain.HabitCheckin) error {
+		if !habits[hc.HabitID] {
+			orphans = append(orphans, *hc)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		log.Printf("found %d orphaned check-ins", len(orphans))
+		return nil
+	}
+	for _, hc := range orphans {
+		if err := stores.Checkins.Delete(hc.HabitID, hc.Date.Format("2006-01-02")); err != nil {
+			return fmt.Errorf("checkin %s|%s: %w", hc.HabitID, hc.Date.Format("2006-01-02"), err)
+		}
+	}
+	log.Printf("removed %d orphaned check-ins", len(orphans))
+	return nil
+}
+
+// parseBackend разбирает "kind:path"; memory не подходит — данные не переживут команду
+func parseBackend(s string) (kind, path string, err error) {
+	kind, path, ok := strings.Cut(s, ":")
+	if !ok || path == "" {
+		return "", "", fmt.Errorf("expected kind:path, got %q", s)
+	}
+	if kind != config.StorageBolt && kind != config.StorageSQLite {
+		return "", "", fmt.Errorf("unsupported backend %q", kind)
+	}
+	if _, err := os.Stat(path); err != nil && !os.IsNotExist(err) {
+		return "", "", err
+	}
+	return kind, path, nil
+}
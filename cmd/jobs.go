@@ -0,0 +1,27 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"habit-tracker-api/internal/service"
+)
+
+// trashPurgeInterval — как часто проверять корзину
+const trashPurgeInterval = time.Hour
+
+// startTrashPurge в фоне периодически удаляет привычки,
+// пролежавшие в корзине дольше retention
+func startTrashPurge(habits *service.HabitService, retention time.Duration) {
+	go func() {
+		for {
+			n, err := habits.PurgeTrash(retention)
+			if err != nil {
+				log.Printf("trash purge: %v", err)
+			} else if n > 0 {
+				log.Printf("trash purge: %d habits removed", n)
+			}
+			time.Sleep(trashPurgeInterval)
+		}
+	}()
+}
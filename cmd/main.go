@@ -2,74 +2,132 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/gin-gonic/gin"
 
 	"habit-tracker-api/internal/auth"
+	"habit-tracker-api/internal/config"
 	"habit-tracker-api/internal/handler"
-	"habit-tracker-api/internal/repository"
+	"habit-tracker-api/internal/mailer"
 	"habit-tracker-api/internal/service"
 )
 
 func main() {
-	// 1) Инициализируем BoltDB
-	repository.InitDB()
-	defer repository.DB.Close()
+	// 0) Загружаем и проверяем конфигурацию
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Служебные подкоманды (migrate, backup, restore …) выполняются вместо запуска сервера
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	// 1) Открываем хранилище (BoltDB или память — по конфигурации)
+	stores, closeStores, err := openStores(cfg)
+	if err != nil {
+		log.Fatalf("failed to open storage: %v", err)
+	}
+	defer closeStores()
 
 	// 2) Зависимости для авторизации
-	userRepo := repository.NewUserRepository()
-	userService := service.NewUserService(userRepo)
+	jwtManager := auth.NewJWTManager(cfg.JWTSecret, cfg.TokenTTL)
+	var mail mailer.Mailer = mailer.NewLogMailer(cfg.MailLogPath)
+	if cfg.Mailer == config.MailerSMTP {
+		mail = mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+	}
+	userService := service.NewUserService(stores.Users, stores.Tokens, jwtManager, cfg.RefreshTTL, mail, cfg.AppURL)
 	userHandler := handler.NewUserHandler(userService)
+	jwtMiddleware := auth.AuthMiddleware(jwtManager, userService)
 
 	// 3) Зависимости для CRUD привычек
-	habitRepo := repository.NewHabitRepository()
-	habitService := service.NewHabitService(habitRepo)
+	habitService := service.NewHabitService(stores.Habits, stores.Users)
 	habitHandler := handler.NewHabitHandler(habitService)
+	startTrashPurge(habitService, cfg.TrashRetention)
 
 	// 4) Создаём Gin-роутер
 	r := gin.Default()
 
-	// 5) Регистрируем публичные маршруты: регистрация и логин
-	userHandler.RegisterRoutes(r)
+	// 5) Регистрируем маршруты входа: регистрация, логин, обновление токена, выход
+	userHandler.RegisterRoutes(r, jwtMiddleware)
 
-	// 6) Регистрируем CRUD для привычек с заглушкой middleware
-	// authMiddleware пока только ставит userEmail в контекст
-	authMiddleware := func(c *gin.Context) {
-		c.Set("userEmail", "test@example.com")
-		c.Next()
+	// 6) Регистрируем CRUD для привычек под настоящим JWT middleware.
+	// Для локальной разработки можно включить заглушку: HABITS_DEV_AUTH=1
+	// (в production конфигурация её не пропустит)
+	authMiddleware := jwtMiddleware
+	if cfg.DevAuth {
+		log.Println("WARNING: /habits uses dev auth stub, JWT is not checked")
+		authMiddleware = auth.DevMiddleware("test@example.com")
 	}
 	habitHandler.RegisterRoutes(r, authMiddleware)
 
 	// Check‑in и Stats
-	checkinRepo := repository.NewHabitCheckinRepository()
-	checkinService := service.NewHabitCheckinService(habitRepo, checkinRepo)
+	checkinService := service.NewHabitCheckinService(stores.Habits, stores.Checkins, stores.Users)
 	checkinHandler := handler.NewHabitCheckinHandler(checkinService)
 
 	// Регистрируем внутри той же группы /habits
 	grp := r.Group("/habits", authMiddleware)
 	{
 		grp.POST("/:id/checkin", checkinHandler.CheckIn)
+		grp.GET("/:id/checkins", checkinHandler.ListCheckins)
+		grp.PATCH("/:id/checkins/:date", checkinHandler.UpdateCheckin)
+		grp.DELETE("/:id/checkins/:date", checkinHandler.DeleteCheckin)
 		grp.GET("/:id/stats", checkinHandler.Stats)
 		grp.GET("/:id/report", checkinHandler.Report)
+		grp.GET("/:id/heatmap", checkinHandler.Heatmap)
 	}
+	r.GET("/dashboard", authMiddleware, checkinHandler.Dashboard)
+	r.GET("/heatmap", authMiddleware, checkinHandler.UserHeatmap)
+
+	// Выгрузка всех данных пользователя
+	exportHandler := handler.NewExportHandler(service.NewExportService(stores.Habits, stores.Checkins, stores.Users))
+	r.GET("/export", authMiddleware, exportHandler.Export)
+
+	// Импорт из Loop Habit Tracker и из нашей же выгрузки
+	importHandler := handler.NewImportHandler(service.NewImportService(stores.Habits, stores.Checkins, stores.Users))
+	r.POST("/import", authMiddleware, importHandler.Import)
 
 	// 7) Пример защищённого route /api/me с настоящим JWT middleware
 	protected := r.Group("/api")
-	protected.Use(auth.AuthMiddleware())
+	protected.Use(jwtMiddleware)
 	{
-		protected.GET("/me", func(c *gin.Context) {
-			email := c.GetString("userEmail")
-			c.JSON(200, gin.H{"email": email})
-		})
+		protected.GET("/me", userHandler.Me)
+		protected.PATCH("/me", userHandler.UpdateProfile)
 	}
 
+	// Лента календаря: адрес выдаётся по JWT, сама лента открыта по секретному токену
+	calendarHandler := handler.NewCalendarHandler(userService, service.NewCalendarService(stores.Users, habitService, checkinService))
+	protected.POST("/me/calendar", calendarHandler.Enable)
+	protected.DELETE("/me/calendar", calendarHandler.Disable)
+	r.GET("/calendar/:token", calendarHandler.Feed)
+
+	// Тепловые карты картинкой для вики и README: адрес выдаётся по JWT,
+	// сами SVG открыты по секретному токену
+	heatmapHandler := handler.NewHeatmapHandler(userService, checkinService)
+	protected.POST("/me/heatmap", heatmapHandler.Enable)
+	protected.DELETE("/me/heatmap", heatmapHandler.Disable)
+	r.GET("/heatmap/:token", heatmapHandler.UserSVG)
+	r.GET("/heatmap/:token/habits/:id", heatmapHandler.HabitSVG)
+
+	// Резервная копия базы — только для администраторов (ADMIN_EMAILS)
+	adminHandler := handler.NewAdminHandler(stores.Snapshot)
+	adminHandler.RegisterRoutes(r, jwtMiddleware, auth.AdminOnly(cfg.AdminEmails, userService))
+
 	// 8) Health‑check на корневом /
 	r.GET("/", func(c *gin.Context) {
-		c.JSON(200, gin.H{"message": "Habit Tracker API running with BoltDB!"})
+		c.JSON(200, gin.H{"message": "Habit Tracker API running with " + cfg.Storage + "!"})
 	})
 
 	// 9) Запуск сервера
-	if err := r.Run(":8080"); err != nil {
+	if err := r.Run(cfg.Addr); err != nil {
 		log.Fatalf("failed to run server: %v", err)
 	}
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"habit-tracker-api/internal/config"
+	"habit-tracker-api/internal/repository"
+	"habit-tracker-api/internal/repository/memory"
+	"habit-tracker-api/internal/repository/sqlite"
+	"habit-tracker-api/internal/service"
+)
+
+// openStores открывает хранилища выбранного в конфигурации бэкенда.
+// Возвращённую функцию нужно вызвать при остановке.
+func openStores(cfg *config.Config) (*service.Stores, func() error, error) {
+	return openBackend(cfg.Storage, cfg.StoragePath())
+}
+
+// openBackend открывает хранилища бэкенда storage (bolt | sqlite | memory) по пути path
+func openBackend(storage, path string) (*service.Stores, func() error, error) {
+	switch storage {
+	case config.StorageMemory:
+		checkins := memory.NewCheckinStore()
+		return &service.Stores{
+			Habits:   memory.NewHabitStore(checkins),
+			Checkins: checkins,
+			Users:    memory.NewUserStore(),
+			Tokens:   memory.NewTokenStore(),
+		}, func() error { return nil }, nil
+	case config.StorageBolt:
+		db, err := repository.InitDB(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open BoltDB: %w", err)
+		}
+		return &service.Stores{
+			Habits:   repository.NewHabitRepository(db),
+			Checkins: repository.NewHabitCheckinRepository(db),
+			Users:    repository.NewUserRepository(db),
+			Tokens:   repository.NewTokenRepository(db),
+			Snapshot: repository.NewSnapshot(db),
+		}, db.Close, nil
+	case config.StorageSQLite:
+		db, err := sqlite.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open SQLite: %w", err)
+		}
+		return &service.Stores{
+			Habits:   sqlite.NewHabitRepository(db),
+			Checkins: sqlite.NewHabitCheckinRepository(db),
+			Users:    sqlite.NewUserRepository(db),
+			Tokens:   sqlite.NewTokenRepository(db),
+		}, db.Close, nil
+	}
+	return nil, nil, fmt.Errorf("unknown storage %q", storage)
+}
@@ -1,44 +1,82 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var jwtKey = []byte("supersecret-key") // В проде хранят в env
-
 // Claims — структура полезной нагрузки JWT
 type Claims struct {
 	Email string `json:"email"`
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT генерирует токен для пользователя
-func GenerateJWT(email string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// JWTManager выпускает и проверяет токены с заданными ключом и временем жизни
+type JWTManager struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewJWTManager — конструктор; secret и ttl приходят из конфигурации
+func NewJWTManager(secret string, ttl time.Duration) *JWTManager {
+	return &JWTManager{key: []byte(secret), ttl: ttl}
+}
+
+// TTL — время жизни выпускаемых access-токенов
+func (m *JWTManager) TTL() time.Duration {
+	return m.ttl
+}
+
+// GenerateJWT генерирует токен для пользователя.
+// У каждого токена свой ID (jti), по которому его можно отозвать.
+func (m *JWTManager) GenerateJWT(email string) (string, error) {
+	now := time.Now()
 	claims := &Claims{
 		Email: email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			ID:        uuid.New().String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.ttl)),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	return token.SignedString(m.key)
 }
 
-// ParseJWT проверяет токен и возвращает email
-func ParseJWT(tokenStr string) (string, error) {
+// ParseJWT проверяет подпись и срок токена и возвращает его claims
+func (m *JWTManager) ParseJWT(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
-		return jwtKey, nil
+		return m.key, nil
 	})
 	if err != nil || !token.Valid {
-		return "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// NewOpaqueToken генерирует случайный токен (refresh, ссылки из писем) и его хэш для хранения
+func NewOpaqueToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", err
 	}
-	return claims.Email, nil
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken — SHA-256 токена; в базе лежат только хэши
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -3,12 +3,19 @@ package auth
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Revoker сообщает, отозван ли access-токен (logout, выход на всех устройствах)
+type Revoker interface {
+	IsRevoked(tokenID, email string, issuedAt time.Time) (bool, error)
+}
+
 // AuthMiddleware — проверяет JWT в заголовке Authorization
-func AuthMiddleware() gin.HandlerFunc {
+// и что токен не отозван
+func AuthMiddleware(jwtManager *JWTManager, revoker Revoker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
@@ -17,14 +24,73 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
-		email, err := ParseJWT(tokenStr)
+		claims, err := jwtManager.ParseJWT(tokenStr)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
 			return
 		}
 
-		// Пробрасываем email в контекст
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		revoked, err := revoker.IsRevoked(claims.ID, claims.Email, issuedAt)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check token"})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token has been revoked"})
+			return
+		}
+
+		// Пробрасываем email и данные токена в контекст (нужны для logout)
+		c.Set("userEmail", claims.Email)
+		c.Set("tokenID", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+		}
+		c.Next()
+	}
+}
+
+// DevMiddleware — заглушка только для локальной разработки: без токена
+// берёт email из заголовка X-Dev-User (или defaultEmail)
+func DevMiddleware(defaultEmail string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := c.GetHeader("X-Dev-User")
+		if email == "" {
+			email = defaultEmail
+		}
 		c.Set("userEmail", email)
 		c.Next()
 	}
 }
+
+// VerifiedChecker сообщает, подтвердил ли пользователь свой email
+type VerifiedChecker interface {
+	IsVerified(email string) (bool, error)
+}
+
+// AdminOnly пропускает только пользователей из списка admins с подтверждённым
+// email: иначе адрес из списка, под которым ещё нет аккаунта, мог бы
+// зарегистрировать кто угодно. Ставится после AuthMiddleware, который кладёт email в контекст.
+func AdminOnly(admins []string, users VerifiedChecker) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(admins))
+	for _, e := range admins {
+		allowed[strings.ToLower(e)] = true
+	}
+	return func(c *gin.Context) {
+		email := c.GetString("userEmail")
+		if !allowed[strings.ToLower(email)] {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access required"})
+			return
+		}
+		verified, err := users.IsVerified(email)
+		if err != nil || !verified {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin email is not verified"})
+			return
+		}
+		c.Next()
+	}
+}
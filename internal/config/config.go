@@ -0,0 +1,322 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+
+	// DefaultJWTSecret — секрет для локальной разработки, в production запрещён
+	DefaultJWTSecret = "supersecret-key"
+
+	StorageBolt   = "bolt"   // BoltDB-файл DBPath
+	StorageSQLite = "sqlite" // файл SQLite SQLitePath
+	StorageMemory = "memory" // в памяти процесса, данные теряются при остановке
+
+	MailerLog  = "log"  // письма пишутся в файл/лог (локальная разработка)
+	MailerSMTP = "smtp" // письма отправляются через SMTP
+)
+
+// Config — настройки приложения
+type Config struct {
+	Env        string        // development | production
+	Addr       string        // адрес HTTP-сервера, например ":8080"
+	Storage    string        // bolt | sqlite | memory
+	DBPath     string        // путь к файлу BoltDB
+	SQLitePath string        // путь к файлу SQLite
+	JWTSecret  string        // ключ подписи JWT
+	TokenTTL   time.Duration // время жизни access-токена
+	RefreshTTL time.Duration // время жизни refresh-токена
+	DevAuth    bool          // заглушка вместо JWT для /habits (только development)
+
+	AdminEmails    []string      // пользователи с доступом к /admin (резервные копии)
+	TrashRetention time.Duration // сколько привычки лежат в корзине до окончательного удаления
+
+	AppURL       string // публичный адрес API для ссылок в письмах
+	Mailer       string // log | smtp
+	MailLogPath  string // файл для log-mailer; пусто — в лог приложения
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
+
+// fileConfig — формат JSON-файла настроек; пустые поля не переопределяют значения.
+// Длительности — в формате time.ParseDuration: "24h", "15m".
+type fileConfig struct {
+	Env        string `json:"env"`
+	Addr       string `json:"addr"`
+	Storage    string `json:"storage"`
+	DBPath     string `json:"db_path"`
+	SQLitePath string `json:"sqlite_path"`
+	JWTSecret  string `json:"jwt_secret"`
+	TokenTTL   string `json:"token_ttl"`
+	RefreshTTL string `json:"refresh_token_ttl"`
+	DevAuth    *bool  `json:"dev_auth"`
+
+	AdminEmails    []string `json:"admin_emails"`
+	TrashRetention string   `json:"trash_retention"`
+
+	AppURL       string `json:"app_url"`
+	Mailer       string `json:"mailer"`
+	MailLogPath  string `json:"mail_log_path"`
+	SMTPHost     string `json:"smtp_host"`
+	SMTPPort     int    `json:"smtp_port"`
+	SMTPUsername string `json:"smtp_username"`
+	SMTPPassword string `json:"smtp_password"`
+	SMTPFrom     string `json:"smtp_from"`
+}
+
+// Default — настройки по умолчанию для локального запуска
+func Default() *Config {
+	return &Config{
+		Env:        EnvDevelopment,
+		Addr:       ":8080",
+		Storage:    StorageBolt,
+		DBPath:     "habit_tracker.db",
+		SQLitePath: "habit_tracker.sqlite",
+		JWTSecret:  DefaultJWTSecret,
+		TokenTTL:   15 * time.Minute,
+		RefreshTTL: 30 * 24 * time.Hour,
+		AppURL:     "http://localhost:8080",
+		Mailer:     MailerLog,
+		SMTPPort:   587,
+
+		TrashRetention: 30 * 24 * time.Hour,
+	}
+}
+
+// Load собирает настройки: значения по умолчанию, затем JSON-файл
+// из CONFIG_FILE (если задан), затем переменные окружения. Результат проверяется.
+func Load() (*Config, error) {
+	cfg := Default()
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return fmt.Errorf("parse config file: %w", err)
+	}
+
+	setString(&c.Env, fc.Env)
+	setString(&c.Addr, fc.Addr)
+	setString(&c.Storage, fc.Storage)
+	setString(&c.DBPath, fc.DBPath)
+	setString(&c.SQLitePath, fc.SQLitePath)
+	setString(&c.JWTSecret, fc.JWTSecret)
+	if err := setDuration(&c.TokenTTL, fc.TokenTTL, "token_ttl"); err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	if err := setDuration(&c.RefreshTTL, fc.RefreshTTL, "refresh_token_ttl"); err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	if fc.DevAuth != nil {
+		c.DevAuth = *fc.DevAuth
+	}
+	if fc.AdminEmails != nil {
+		c.AdminEmails = fc.AdminEmails
+	}
+	if err := setDuration(&c.TrashRetention, fc.TrashRetention, "trash_retention"); err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	setString(&c.AppURL, fc.AppURL)
+	setString(&c.Mailer, fc.Mailer)
+	setString(&c.MailLogPath, fc.MailLogPath)
+	setString(&c.SMTPHost, fc.SMTPHost)
+	if fc.SMTPPort != 0 {
+		c.SMTPPort = fc.SMTPPort
+	}
+	setString(&c.SMTPUsername, fc.SMTPUsername)
+	setString(&c.SMTPPassword, fc.SMTPPassword)
+	setString(&c.SMTPFrom, fc.SMTPFrom)
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	setString(&c.Env, os.Getenv("APP_ENV"))
+	if v := os.Getenv("PORT"); v != "" {
+		c.Addr = ":" + v
+	}
+	setString(&c.Addr, os.Getenv("HTTP_ADDR"))
+	setString(&c.Storage, os.Getenv("STORAGE"))
+	setString(&c.DBPath, os.Getenv("DB_PATH"))
+	setString(&c.SQLitePath, os.Getenv("SQLITE_PATH"))
+	setString(&c.JWTSecret, os.Getenv("JWT_SECRET"))
+	if err := setDuration(&c.TokenTTL, os.Getenv("TOKEN_TTL"), "TOKEN_TTL"); err != nil {
+		return err
+	}
+	if err := setDuration(&c.RefreshTTL, os.Getenv("REFRESH_TOKEN_TTL"), "REFRESH_TOKEN_TTL"); err != nil {
+		return err
+	}
+	if err := setBool(&c.DevAuth, os.Getenv("HABITS_DEV_AUTH"), "HABITS_DEV_AUTH"); err != nil {
+		return err
+	}
+	// ADMIN_EMAILS — список через запятую
+	if v := os.Getenv("ADMIN_EMAILS"); v != "" {
+		c.AdminEmails = nil
+		for _, e := range strings.Split(v, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				c.AdminEmails = append(c.AdminEmails, e)
+			}
+		}
+	}
+	if err := setDuration(&c.TrashRetention, os.Getenv("TRASH_RETENTION"), "TRASH_RETENTION"); err != nil {
+		return err
+	}
+
+	setString(&c.AppURL, os.Getenv("APP_URL"))
+	setString(&c.Mailer, os.Getenv("MAILER"))
+	setString(&c.MailLogPath, os.Getenv("MAIL_LOG_PATH"))
+	setString(&c.SMTPHost, os.Getenv("SMTP_HOST"))
+	if err := setInt(&c.SMTPPort, os.Getenv("SMTP_PORT"), "SMTP_PORT"); err != nil {
+		return err
+	}
+	setString(&c.SMTPUsername, os.Getenv("SMTP_USERNAME"))
+	setString(&c.SMTPPassword, os.Getenv("SMTP_PASSWORD"))
+	setString(&c.SMTPFrom, os.Getenv("SMTP_FROM"))
+	return nil
+}
+
+// Validate проверяет настройки перед стартом
+func (c *Config) Validate() error {
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		return fmt.Errorf("unknown env %q", c.Env)
+	}
+	if c.Addr == "" {
+		return errors.New("addr is required")
+	}
+	switch c.Storage {
+	case StorageBolt:
+		if c.DBPath == "" {
+			return errors.New("db path is required")
+		}
+	case StorageSQLite:
+		if c.SQLitePath == "" {
+			return errors.New("sqlite path is required")
+		}
+	case StorageMemory:
+		if c.IsProduction() {
+			return errors.New("memory storage is not allowed in production")
+		}
+	default:
+		return fmt.Errorf("unknown storage %q", c.Storage)
+	}
+	if c.JWTSecret == "" {
+		return errors.New("jwt secret is required")
+	}
+	if c.TokenTTL <= 0 {
+		return errors.New("token ttl must be positive")
+	}
+	if c.RefreshTTL <= c.TokenTTL {
+		return errors.New("refresh token ttl must be longer than token ttl")
+	}
+	if c.TrashRetention <= 0 {
+		return errors.New("trash retention must be positive")
+	}
+	switch c.Mailer {
+	case MailerLog:
+		// в логе оказались бы токены сброса пароля и подтверждения email
+		if c.IsProduction() {
+			return errors.New("log mailer is not allowed in production")
+		}
+	case MailerSMTP:
+		if c.SMTPHost == "" || c.SMTPFrom == "" {
+			return errors.New("smtp mailer requires smtp host and from address")
+		}
+	default:
+		return fmt.Errorf("unknown mailer %q", c.Mailer)
+	}
+	if c.IsProduction() {
+		if c.JWTSecret == DefaultJWTSecret {
+			return errors.New("refusing to start in production with the default JWT secret")
+		}
+		if c.DevAuth {
+			return errors.New("dev auth stub is not allowed in production")
+		}
+	}
+	return nil
+}
+
+// StoragePath — путь к файлу выбранного хранилища (пусто для memory)
+func (c *Config) StoragePath() string {
+	switch c.Storage {
+	case StorageBolt:
+		return c.DBPath
+	case StorageSQLite:
+		return c.SQLitePath
+	}
+	return ""
+}
+
+// IsProduction — запущено ли приложение в боевом режиме
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+func setString(dst *string, v string) {
+	if v != "" {
+		*dst = v
+	}
+}
+
+func setDuration(dst *time.Duration, v, name string) error {
+	if v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*dst = d
+	return nil
+}
+
+func setBool(dst *bool, v, name string) error {
+	if v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*dst = b
+	return nil
+}
+
+func setInt(dst *int, v, name string) error {
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*dst = n
+	return nil
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "errors"
+
+// Ошибки хранилищ «записи нет»: сервисы отличают их от сбоев чтения и записи
+var (
+	ErrHabitNotFound   = errors.New("habit not found")
+	ErrCheckinNotFound = errors.New("checkin not found")
+)
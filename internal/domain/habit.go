@@ -7,5 +7,55 @@ type Habit struct {
 	UserEmail string    `json:"user_email"`
 	Name      string    `json:"name"`
 	Goal      string    `json:"goal"`
+	Schedule  Schedule  `json:"schedule"`
+	Target    float64   `json:"target,omitempty"` // дневная цель; 0 — привычка «сделал/не сделал»
+	Unit      string    `json:"unit,omitempty"`   // единица измерения цели: "стаканов", "km"…
 	CreatedAt time.Time `json:"created_at"`
+
+	ArchivedAt *time.Time `json:"archived_at,omitempty"` // в архиве: история хранится, в статистику не идёт
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`  // в корзине: можно восстановить до очистки
+
+	// Дни отдыха (YYYY-MM-DD, по возрастанию): расписание их не требует,
+	// и пропуск такого дня не прерывает серию
+	FreezeDays []string `json:"freeze_days,omitempty"`
+}
+
+// HabitStatus — состояние привычки
+type HabitStatus string
+
+const (
+	HabitActive   HabitStatus = "active"
+	HabitArchived HabitStatus = "archived"
+	HabitTrashed  HabitStatus = "trashed"
+)
+
+// Status — текущее состояние: корзина важнее архива
+func (h *Habit) Status() HabitStatus {
+	switch {
+	case h.DeletedAt != nil:
+		return HabitTrashed
+	case h.ArchivedAt != nil:
+		return HabitArchived
+	}
+	return HabitActive
+}
+
+// ScheduleType — вид расписания привычки
+type ScheduleType string
+
+const (
+	ScheduleDaily         ScheduleType = "daily"           // каждый день
+	ScheduleWeekdays      ScheduleType = "weekdays"        // в определённые дни недели
+	ScheduleTimesPerWeek  ScheduleType = "times_per_week"  // N раз в неделю
+	ScheduleTimesPerMonth ScheduleType = "times_per_month" // N раз в месяц
+	ScheduleEveryNDays    ScheduleType = "every_n_days"    // раз в N дней
+)
+
+// Schedule — как часто нужно выполнять привычку.
+// Пустой Type (старые записи) означает ежедневное расписание.
+type Schedule struct {
+	Type     ScheduleType   `json:"type"`
+	Weekdays []time.Weekday `json:"weekdays,omitempty"` // для weekdays: 0 — воскресенье … 6 — суббота
+	Times    int            `json:"times,omitempty"`    // для times_per_week / times_per_month
+	Interval int            `json:"interval,omitempty"` // для every_n_days
 }
@@ -7,5 +7,15 @@ type HabitCheckin struct {
 	ID      string    `json:"id"`
 	HabitID string    `json:"habit_id"`
 	Date    time.Time `json:"date"`              // обычно без времени
+	Amount  float64   `json:"amount"`            // сколько сделано за день (сумма частичных отметок)
 	Comment string    `json:"comment,omitempty"` // опционально
 }
+
+// AmountOrOne — количество отметки; у записей, сохранённых до появления
+// количества (Amount = 0), это одна отметка
+func (c *HabitCheckin) AmountOrOne() float64 {
+	if c.Amount <= 0 {
+		return 1
+	}
+	return c.Amount
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// RefreshToken — выданный refresh-токен; хранится только хэш самого токена
+type RefreshToken struct {
+	Hash      string    `json:"hash"`
+	UserEmail string    `json:"user_email"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
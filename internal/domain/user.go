@@ -3,8 +3,15 @@ package domain
 import "time"
 
 type User struct {
-	ID        uint   `gorm:"primaryKey"`
-	Email     string `gorm:"uniqueIndex;not null"`
-	Password  string `gorm:"not null"`
-	CreatedAt time.Time
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Password  string    `json:"password"` // bcrypt-хэш
+	Timezone  string    `json:"timezone"` // IANA-имя, например "Europe/Moscow"; пусто — UTC
+	Verified  bool      `json:"verified"` // email подтверждён по ссылке из письма
+	CreatedAt time.Time `json:"created_at"`
+
+	// SHA-256 секретного токена ленты календаря (iCal); пусто — лента выключена
+	CalendarToken string `json:"calendar_token,omitempty"`
+	// SHA-256 секретного токена публичной тепловой карты (SVG); пусто — карта закрыта
+	HeatmapToken string `json:"heatmap_token,omitempty"`
 }
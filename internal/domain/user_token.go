@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Назначения одноразовых токенов из писем
+const (
+	TokenPasswordReset = "password_reset"
+	TokenEmailVerify   = "email_verify"
+)
+
+// UserToken — одноразовый токен со сроком действия (сброс пароля, подтверждение email);
+// хранится только хэш самого токена
+type UserToken struct {
+	Hash      string    `json:"hash"`
+	UserEmail string    `json:"user_email"`
+	Purpose   string    `json:"purpose"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler — служебные маршруты администратора
+type AdminHandler struct {
+	snapshot io.WriterTo // nil, если хранилище не поддерживает онлайн-копию
+}
+
+func NewAdminHandler(snapshot io.WriterTo) *AdminHandler {
+	return &AdminHandler{snapshot}
+}
+
+// Backup — отдаёт согласованную копию базы файлом, не останавливая сервер
+func (h *AdminHandler) Backup(c *gin.Context) {
+	if h.snapshot == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "backup is not supported by this storage"})
+		return
+	}
+	name := "habit_tracker-" + time.Now().UTC().Format("20060102T150405Z") + ".db"
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	c.Status(http.StatusOK)
+	// Заголовки уже отправлены: при ошибке остаётся только оборвать ответ
+	if _, err := h.snapshot.WriteTo(c.Writer); err != nil {
+		log.Printf("backup: %v", err)
+		c.Abort()
+	}
+}
+
+// RegisterRoutes — маршруты /admin; authMiddleware должен проверять права администратора
+func (h *AdminHandler) RegisterRoutes(r *gin.Engine, authMiddleware ...gin.HandlerFunc) {
+	grp := r.Group("/admin", authMiddleware...)
+	{
+		grp.GET("/backup", h.Backup)
+	}
+}
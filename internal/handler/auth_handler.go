@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	"habit-tracker-api/internal/service"
 
@@ -19,6 +20,7 @@ func NewUserHandler(s *service.UserService) *UserHandler {
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	Timezone string `json:"timezone"` // IANA-имя, например "Europe/Moscow"
 }
 
 type LoginRequest struct {
@@ -34,8 +36,12 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Register(req.Email, req.Password); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Register(req.Email, req.Password, req.Timezone); err != nil {
+		status := http.StatusInternalServerError
+		if isValidation(err) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -49,18 +55,181 @@ func (h *UserHandler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	token, err := h.service.Login(req.Email, req.Password)
+	tokens, err := h.service.Login(req.Email, req.Password)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RefreshRequest — тело POST /token/refresh
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// Refresh — обмен refresh-токена на новую пару токенов
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tokens, err := h.service.Refresh(req.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// LogoutRequest — тело POST /logout
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	All          bool   `json:"all"` // выйти на всех устройствах
+}
+
+// Logout — отзыв текущего токена (или всех токенов пользователя)
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	// тело необязательно
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	email := c.GetString("userEmail")
+	if req.All {
+		if err := h.service.LogoutAll(email); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	expiresAt, _ := c.Get("tokenExpiresAt")
+	exp, _ := expiresAt.(time.Time)
+	if err := h.service.Logout(email, c.GetString("tokenID"), exp, req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "logged out"})
+}
+
+// UpdateProfileRequest — тело PATCH /api/me
+type UpdateProfileRequest struct {
+	Timezone string `json:"timezone" binding:"required"`
+}
+
+// Me — профиль текущего пользователя
+func (h *UserHandler) Me(c *gin.Context) {
+	email := c.GetString("userEmail")
+	user, err := h.service.GetByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"email": user.Email, "timezone": user.Timezone, "verified": user.Verified})
+}
+
+// UpdateProfile — смена часового пояса пользователя
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	email := c.GetString("userEmail")
+	if err := h.service.UpdateTimezone(email, req.Timezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"email": email, "timezone": req.Timezone})
+}
+
+// ForgotPasswordRequest — тело POST /password/forgot
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest — тело POST /password/reset
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// VerifyEmailRequest — тело POST /email/verify
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPassword — письмо со ссылкой для сброса пароля.
+// Ответ одинаковый, есть такой пользователь или нет.
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.RequestPasswordReset(req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send email"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "if the account exists, a reset email has been sent"})
+}
+
+// ResetPassword — новый пароль по токену из письма
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.ResetPassword(req.Token, req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "password has been reset"})
+}
+
+// VerifyEmail — подтверждение email: POST с токеном в теле
+// или GET /email/verify?token=... по ссылке из письма
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if c.Request.Method == http.MethodPost {
+		var req VerifyEmailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		token = req.Token
+	}
+	if err := h.service.VerifyEmail(token); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+// ResendVerification — повторное письмо для подтверждения email
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	if err := h.service.SendVerification(c.GetString("userEmail")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "verification email sent"})
 }
 
 // RegisterRoutes — подключение маршрутов к gin.Engine
-func (h *UserHandler) RegisterRoutes(r *gin.Engine) {
+func (h *UserHandler) RegisterRoutes(r *gin.Engine, authMiddleware gin.HandlerFunc) {
 	r.POST("/register", h.Register)
 	r.POST("/login", h.Login)
+	r.POST("/token/refresh", h.Refresh)
+	r.POST("/logout", authMiddleware, h.Logout)
+	r.POST("/password/forgot", h.ForgotPassword)
+	r.POST("/password/reset", h.ResetPassword)
+	r.GET("/email/verify", h.VerifyEmail)
+	r.POST("/email/verify", h.VerifyEmail)
+	r.POST("/email/verify/resend", authMiddleware, h.ResendVerification)
 
 	// Временный роут, чтобы убрать warning "не используется"
 	r.GET("/users", func(c *gin.Context) {
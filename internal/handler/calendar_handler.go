@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"habit-tracker-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarHandler — лента привычек для календарей (iCal) и управление её адресом
+type CalendarHandler struct {
+	users    *service.UserService
+	calendar *service.CalendarService
+}
+
+func NewCalendarHandler(users *service.UserService, calendar *service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{users, calendar}
+}
+
+// Enable — POST /api/me/calendar: новый секретный адрес ленты (старый перестаёт работать)
+func (h *CalendarHandler) Enable(c *gin.Context) {
+	url, err := h.users.EnableCalendar(c.GetString("userEmail"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"url": url})
+}
+
+// Disable — DELETE /api/me/calendar: выключает ленту
+func (h *CalendarHandler) Disable(c *gin.Context) {
+	if err := h.users.DisableCalendar(c.GetString("userEmail")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "calendar disabled"})
+}
+
+// Feed — GET /calendar/:token.ics без авторизации: секретом служит сам адрес
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	cal, err := h.calendar.Calendar(token)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCalendarNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	// лента небольшая: собираем целиком, чтобы при ошибке ответить JSON
+	var buf bytes.Buffer
+	if err := renderCalendarICS(&buf, cal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="habits.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+	"habit-tracker-api/internal/service"
+)
+
+// icsDomain — правая часть UID событий ленты
+const icsDomain = "habit-tracker-api"
+
+// icsWeekdays — дни недели в RRULE, индекс — time.Weekday
+var icsWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// icsWriter пишет строки iCalendar (RFC 5545): CRLF и перенос длинных строк;
+// первая ошибка записи запоминается, остальные строки пропускаются
+type icsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *icsWriter) line(name, value string) {
+	if w.err != nil {
+		return
+	}
+	s := name + ":" + value
+	// строка не длиннее 75 байт; продолжение начинается с пробела,
+	// многобайтовые символы UTF-8 не разрываются
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+	_, w.err = io.WriteString(w.w, b.String())
+}
+
+func utf8Start(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+// icsText экранирует значение текстового свойства
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// icsDate переводит YYYY-MM-DD в формат DATE (YYYYMMDD)
+func icsDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// renderCalendarICS пишет ленту: по повторяющемуся событию на привычку
+// и по событию «выполнено» на каждый выполненный день
+func renderCalendarICS(out io.Writer, cal *service.Calendar) error {
+	w := &icsWriter{w: out}
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//"+icsDomain+"//Habits//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.line("X-WR-CALNAME", "Habits")
+	w.line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	w.line("X-PUBLISHED-TTL", "PT1H")
+	for _, ch := range cal.Habits {
+		if err := writeHabitEvent(w, ch, stamp); err != nil {
+			return err
+		}
+		for _, d := range ch.Done {
+			day, err := time.Parse("2006-01-02", d.Date)
+			if err != nil {
+				return err
+			}
+			summary := "✓ " + ch.Habit.Name
+			if ch.Habit.Target > 0 {
+				summary += ": " + strconv.FormatFloat(d.Amount, 'f', -1, 64)
+				if ch.Habit.Unit != "" {
+					summary += " " + ch.Habit.Unit
+				}
+			}
+			w.line("BEGIN", "VEVENT")
+			w.line("UID", ch.Habit.ID+"-"+icsDate(day)+"@"+icsDomain)
+			w.line("DTSTAMP", stamp)
+			w.line("DTSTART;VALUE=DATE", icsDate(day))
+			w.line("DTEND;VALUE=DATE", icsDate(day.AddDate(0, 0, 1)))
+			w.line("SUMMARY", icsText(summary))
+			w.line("RELATED-TO", "habit-"+ch.Habit.ID+"@"+icsDomain)
+			w.line("STATUS", "CONFIRMED")
+			w.line("TRANSP", "TRANSPARENT")
+			w.line("END", "VEVENT")
+		}
+	}
+	w.line("END", "VCALENDAR")
+	return w.err
+}
+
+// writeHabitEvent пишет повторяющееся событие привычки по её расписанию.
+// Расписания «N раз в неделю/месяц» не привязаны к дням, поэтому событие
+// занимает всю неделю (с понедельника) или стоит первым числом месяца.
+func writeHabitEvent(w *icsWriter, ch service.CalendarHabit, stamp string) error {
+	h := ch.Habit
+	start, err := time.Parse("2006-01-02", ch.Start)
+	if err != nil {
+		return err
+	}
+	s := h.Schedule
+	length := 1
+	summary := h.Name
+	var rule string
+	switch s.Type {
+	case domain.ScheduleWeekdays:
+		days := make([]string, 0, len(s.Weekdays))
+		on := make(map[time.Weekday]bool, len(s.Weekdays))
+		for _, d := range s.Weekdays {
+			days = append(days, icsWeekdays[d])
+			on[d] = true
+		}
+		rule = "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+		// DTSTART должен совпадать с первым повторением
+		for !on[start.Weekday()] {
+			start = start.AddDate(0, 0, 1)
+		}
+	case domain.ScheduleTimesPerWeek:
+		rule = "FREQ=WEEKLY"
+		start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		length = 7
+		summary += fmt.Sprintf(" (%d× a week)", s.Times)
+	case domain.ScheduleTimesPerMonth:
+		rule = "FREQ=MONTHLY"
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+		summary += fmt.Sprintf(" (%d× a month)", s.Times)
+	case domain.ScheduleEveryNDays:
+		rule = "FREQ=DAILY;INTERVAL=" + strconv.Itoa(s.Interval)
+	default:
+		rule = "FREQ=DAILY"
+	}
+	if ch.Until != "" {
+		until, err := time.Parse("2006-01-02", ch.Until)
+		if err != nil {
+			return err
+		}
+		rule += ";UNTIL=" + icsDate(until)
+	}
+
+	description := h.Goal
+	if h.Target > 0 {
+		goal := "Daily target: " + strconv.FormatFloat(h.Target, 'f', -1, 64)
+		if h.Unit != "" {
+			goal += " " + h.Unit
+		}
+		description = strings.TrimSpace(description + "\n" + goal)
+	}
+
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", "habit-"+h.ID+"@"+icsDomain)
+	w.line("DTSTAMP", stamp)
+	w.line("DTSTART;VALUE=DATE", icsDate(start))
+	w.line("DTEND;VALUE=DATE", icsDate(start.AddDate(0, 0, length)))
+	w.line("RRULE", rule)
+	w.line("SUMMARY", icsText(summary))
+	if description != "" {
+		w.line("DESCRIPTION", icsText(description))
+	}
+	w.line("TRANSP", "TRANSPARENT")
+	w.line("END", "VEVENT")
+	return w.err
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"habit-tracker-api/internal/domain"
+	"habit-tracker-api/internal/service"
+)
+
+// unfold склеивает перенесённые строки обратно (RFC 5545, 3.1)
+func unfold(s string) string {
+	return strings.ReplaceAll(s, "\r\n ", "")
+}
+
+func TestICSLineFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		lines int // физических строк после переноса
+	}{
+		{"short", "Read", 1},
+		{"exactly 75 octets", strings.Repeat("a", 75-len("SUMMARY:")), 1},
+		{"76 octets", strings.Repeat("a", 76-len("SUMMARY:")), 2},
+		{"long ASCII", strings.Repeat("a", 200), 3},
+		// 2-байтовые символы: граница 75 приходится на середину буквы
+		{"cyrillic", strings.Repeat("ж", 100), 3},
+		// 4-байтовые символы не разрываются ни на одной границе
+		{"emoji", "x" + strings.Repeat("🏃", 40), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &icsWriter{w: &buf}
+			w.line("SUMMARY", tt.value)
+			if w.err != nil {
+				t.Fatal(w.err)
+			}
+			out := buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("line does not end with CRLF: %q", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(lines) != tt.lines {
+				t.Errorf("got %d lines, want %d", len(lines), tt.lines)
+			}
+			for i, l := range lines {
+				if len(l) > 75 {
+					t.Errorf("line %d is %d octets long", i, len(l))
+				}
+				if i > 0 && !strings.HasPrefix(l, " ") {
+					t.Errorf("continuation %d does not start with a space: %q", i, l)
+				}
+				if !utf8.ValidString(l) {
+					t.Errorf("line %d splits a UTF-8 character: %q", i, l)
+				}
+			}
+			if got := unfold(out); got != "SUMMARY:"+tt.value+"\r\n" {
+				t.Errorf("unfolded = %q", got)
+			}
+		})
+	}
+}
+
+func TestICSText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Read", "Read"},
+		{"Read, write; repeat", `Read\, write\; repeat`},
+		{`C:\habits`, `C:\\habits`},
+		{"line1\nline2", `line1\nline2`},
+		{"line1\r\nline2", `line1\nline2`},
+		// обратная косая черта экранируется раньше остального, а не после
+		{`\n;`, `\\n\;`},
+	}
+	for _, tt := range tests {
+		if got := icsText(tt.in); got != tt.want {
+			t.Errorf("icsText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// Текст привычки экранируется во всех событиях ленты
+func TestRenderCalendarICSEscapes(t *testing.T) {
+	cal := &service.Calendar{Habits: []service.CalendarHabit{{
+		Habit: &domain.Habit{ID: "h1", Name: "Water, cold; fresh", Goal: "Stay\nhydrated", Target: 2, Unit: "l",
+			Schedule: domain.Schedule{Type: domain.ScheduleWeekdays, Weekdays: []time.Weekday{time.Monday}}},
+		Start: "2026-10-15", // четверг: первое повторение — 19-го
+		Until: "2026-11-01",
+		Done:  []service.CalendarDay{{Date: "2026-10-19", Amount: 1.5}},
+	}}}
+	var buf bytes.Buffer
+	if err := renderCalendarICS(&buf, cal); err != nil {
+		t.Fatal(err)
+	}
+	out := unfold(buf.String())
+	for _, want := range []string{
+		"DTSTART;VALUE=DATE:20261019\r\n",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20261101\r\n",
+		`SUMMARY:Water\, cold\; fresh` + "\r\n",
+		`DESCRIPTION:Stay\nhydrated\nDaily target: 2 l` + "\r\n",
+		`SUMMARY:✓ Water\, cold\; fresh: 1.5 l` + "\r\n",
+		"UID:h1-20261019@" + icsDomain + "\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"habit-tracker-api/internal/service"
+)
+
+// habitErrorStatus подбирает HTTP-статус для ошибки сервисов привычек:
+// неверные данные — 400, чужая привычка — 403, несуществующая привычка или отметка — 404,
+// архивная — 409, остальное — fallback
+func habitErrorStatus(err error, fallback int) int {
+	switch {
+	case isValidation(err):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrHabitNotFound), errors.Is(err, service.ErrCheckinNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrHabitArchived):
+		return http.StatusConflict
+	}
+	return fallback
+}
+
+func isValidation(err error) bool {
+	var ve *service.ValidationError
+	return errors.As(err, &ve)
+}
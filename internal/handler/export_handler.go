@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"habit-tracker-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler — выгрузка данных пользователя
+type ExportHandler struct {
+	service *service.ExportService
+}
+
+func NewExportHandler(s *service.ExportService) *ExportHandler {
+	return &ExportHandler{s}
+}
+
+// Export — GET /export: ZIP с habits.csv, checkins.csv и data.json
+func (h *ExportHandler) Export(c *gin.Context) {
+	name := "habits-export-" + time.Now().UTC().Format("20060102") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	// Архив пишется прямо в ответ: после первых байт поменять статус уже нельзя
+	if err := h.service.Export(c.GetString("userEmail"), c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("export: %v", err)
+		c.Abort()
+	}
+}
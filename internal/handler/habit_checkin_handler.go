@@ -18,30 +18,78 @@ func NewHabitCheckinHandler(s *service.HabitCheckinService) *HabitCheckinHandler
 
 // CheckinRequest — тело POST /habits/:id/checkin
 type CheckinRequest struct {
-	Comment string `json:"comment"`
+	Date    string  `json:"date"` // YYYY-MM-DD; по умолчанию — сегодня
+	Comment string  `json:"comment"`
+	Amount  float64 `json:"amount"` // частичная отметка; по умолчанию 1
 }
 
 // POST /habits/:id/checkin
 func (h *HabitCheckinHandler) CheckIn(c *gin.Context) {
+	userEmail := c.GetString("userEmail")
 	habitID := c.Param("id")
 	var req CheckinRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.service.CheckIn(habitID, req.Comment); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.service.CheckIn(userEmail, habitID, req.Date, req.Comment, req.Amount); err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, gin.H{"message": "checked in"})
 }
 
+// UpdateCheckinRequest — тело PATCH /habits/:id/checkins/:date
+type UpdateCheckinRequest struct {
+	Comment *string  `json:"comment"`
+	Amount  *float64 `json:"amount"`
+}
+
+// GET /habits/:id/checkins?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *HabitCheckinHandler) ListCheckins(c *gin.Context) {
+	userEmail := c.GetString("userEmail")
+	habitID := c.Param("id")
+	checks, err := h.service.ListCheckins(userEmail, habitID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": checks})
+}
+
+// PATCH /habits/:id/checkins/:date
+func (h *HabitCheckinHandler) UpdateCheckin(c *gin.Context) {
+	userEmail := c.GetString("userEmail")
+	var req UpdateCheckinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	hc, err := h.service.UpdateCheckin(userEmail, c.Param("id"), c.Param("date"), req.Comment, req.Amount)
+	if err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, hc)
+}
+
+// DELETE /habits/:id/checkins/:date
+func (h *HabitCheckinHandler) DeleteCheckin(c *gin.Context) {
+	userEmail := c.GetString("userEmail")
+	if err := h.service.DeleteCheckin(userEmail, c.Param("id"), c.Param("date")); err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "checkin deleted"})
+}
+
 // GET /habits/:id/stats
 func (h *HabitCheckinHandler) Stats(c *gin.Context) {
+	userEmail := c.GetString("userEmail")
 	habitID := c.Param("id")
-	streak, total, possible, rate, err := h.service.Stats(habitID)
+	streak, total, possible, rate, err := h.service.Stats(userEmail, habitID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(habitErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
@@ -54,12 +102,44 @@ func (h *HabitCheckinHandler) Stats(c *gin.Context) {
 
 // internal/handler/habit_checkin_handler.go
 
+// GET /habits/:id/report?from=YYYY-MM-DD&to=YYYY-MM-DD (диапазон необязателен)
 func (h *HabitCheckinHandler) Report(c *gin.Context) {
+	userEmail := c.GetString("userEmail")
 	habitID := c.Param("id")
-	report, err := h.service.Report(habitID)
+	report, err := h.service.Report(userEmail, habitID, c.Query("from"), c.Query("to"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(habitErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, report)
 }
+
+// GET /dashboard — сводка по всем активным привычкам на сегодня
+func (h *HabitCheckinHandler) Dashboard(c *gin.Context) {
+	dashboard, err := h.service.Dashboard(c.GetString("userEmail"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// GET /habits/:id/heatmap?from=YYYY-MM-DD&to=YYYY-MM-DD — выполнение по дням
+func (h *HabitCheckinHandler) Heatmap(c *gin.Context) {
+	hm, err := h.service.Heatmap(c.GetString("userEmail"), c.Param("id"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, hm)
+}
+
+// GET /heatmap — выполнение по дням по всем активным привычкам
+func (h *HabitCheckinHandler) UserHeatmap(c *gin.Context) {
+	hm, err := h.service.UserHeatmap(c.GetString("userEmail"), c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, hm)
+}
@@ -23,8 +23,11 @@ func NewHabitHandler(s *service.HabitService) *HabitHandler {
 
 // CreateHabitRequest — тело POST /habits
 type CreateHabitRequest struct {
-	Name string `json:"name" binding:"required"`
-	Goal string `json:"goal"`
+	Name     string           `json:"name" binding:"required"`
+	Goal     string           `json:"goal"`
+	Schedule *domain.Schedule `json:"schedule"` // по умолчанию — каждый день
+	Target   float64          `json:"target"`   // дневная цель, 0 — без цели
+	Unit     string           `json:"unit"`
 }
 
 // CreateHabit — создаёт новую привычку
@@ -41,11 +44,16 @@ func (h *HabitHandler) CreateHabit(c *gin.Context) {
 		UserEmail: userEmail,
 		Name:      req.Name,
 		Goal:      req.Goal,
+		Target:    req.Target,
+		Unit:      req.Unit,
 		CreatedAt: time.Now(),
 	}
+	if req.Schedule != nil {
+		habit.Schedule = *req.Schedule
+	}
 
 	if err := h.service.Create(habit); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(habitErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, habit)
@@ -55,13 +63,14 @@ func (h *HabitHandler) CreateHabit(c *gin.Context) {
 func (h *HabitHandler) GetHabits(c *gin.Context) {
 	userEmail := c.GetString("userEmail")
 
+	status := c.Query("status") // active (по умолчанию) | archived | trashed | all
 	name := c.Query("name")
 	dateFrom := c.Query("date_from")
 	dateTo := c.Query("date_to")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	habits, err := h.service.ListHabits(userEmail, name, dateFrom, dateTo, page, pageSize)
+	habits, err := h.service.ListHabits(userEmail, status, name, dateFrom, dateTo, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -77,10 +86,11 @@ func (h *HabitHandler) GetHabits(c *gin.Context) {
 
 // GetHabit — возвращает одну привычку по ID
 func (h *HabitHandler) GetHabit(c *gin.Context) {
+	userEmail := c.GetString("userEmail")
 	id := c.Param("id")
-	habit, err := h.service.GetByID(id)
+	habit, err := h.service.GetByID(userEmail, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "habit not found"})
+		c.JSON(habitErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, habit)
@@ -88,6 +98,7 @@ func (h *HabitHandler) GetHabit(c *gin.Context) {
 
 // UpdateHabit — обновляет привычку по ID
 func (h *HabitHandler) UpdateHabit(c *gin.Context) {
+	userEmail := c.GetString("userEmail")
 	id := c.Param("id")
 	var req CreateHabitRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -95,29 +106,104 @@ func (h *HabitHandler) UpdateHabit(c *gin.Context) {
 		return
 	}
 
-	existing, err := h.service.GetByID(id)
+	existing, err := h.service.GetByID(userEmail, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "habit not found"})
+		c.JSON(habitErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
 		return
 	}
 	existing.Name = req.Name
 	existing.Goal = req.Goal
+	existing.Target = req.Target
+	existing.Unit = req.Unit
+	if req.Schedule != nil {
+		existing.Schedule = *req.Schedule
+	}
 
-	if err := h.service.Update(existing); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.service.Update(userEmail, existing); err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, existing)
 }
 
-// DeleteHabit — удаляет привычку по ID
+// DeleteHabit — переносит привычку в корзину;
+// с ?permanent=true удаляет сразу и навсегда (в том числе из корзины)
 func (h *HabitHandler) DeleteHabit(c *gin.Context) {
+	userEmail := c.GetString("userEmail")
 	id := c.Param("id")
-	if err := h.service.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if c.Query("permanent") == "true" {
+		if err := h.service.DeletePermanently(userEmail, id); err != nil {
+			c.JSON(habitErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "habit deleted"})
+		return
+	}
+	if err := h.service.Delete(userEmail, id); err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "habit moved to trash"})
+}
+
+// ArchiveHabit — ставит привычку на паузу
+func (h *HabitHandler) ArchiveHabit(c *gin.Context) {
+	habit, err := h.service.Archive(c.GetString("userEmail"), c.Param("id"))
+	if err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "habit deleted"})
+	c.JSON(http.StatusOK, habit)
+}
+
+// UnarchiveHabit — возвращает привычку из архива
+func (h *HabitHandler) UnarchiveHabit(c *gin.Context) {
+	habit, err := h.service.Unarchive(c.GetString("userEmail"), c.Param("id"))
+	if err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, habit)
+}
+
+// RestoreHabit — возвращает привычку из корзины
+func (h *HabitHandler) RestoreHabit(c *gin.Context) {
+	habit, err := h.service.Restore(c.GetString("userEmail"), c.Param("id"))
+	if err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, habit)
+}
+
+// FreezeRequest — тело POST /habits/:id/freezes
+type FreezeRequest struct {
+	Date string `json:"date" binding:"required"` // YYYY-MM-DD
+}
+
+// AddFreeze — добавляет день отдыха, который не прерывает серию
+func (h *HabitHandler) AddFreeze(c *gin.Context) {
+	var req FreezeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	habit, err := h.service.AddFreeze(c.GetString("userEmail"), c.Param("id"), req.Date)
+	if err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, habit)
+}
+
+// RemoveFreeze — убирает день отдыха
+func (h *HabitHandler) RemoveFreeze(c *gin.Context) {
+	habit, err := h.service.RemoveFreeze(c.GetString("userEmail"), c.Param("id"), c.Param("date"))
+	if err != nil {
+		c.JSON(habitErrorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, habit)
 }
 
 // RegisterRoutes — привязывает маршруты CRUD привычек к роутеру
@@ -129,5 +215,10 @@ func (h *HabitHandler) RegisterRoutes(r *gin.Engine, authMiddleware gin.HandlerF
 		grp.GET("/:id", h.GetHabit)
 		grp.PUT("/:id", h.UpdateHabit)
 		grp.DELETE("/:id", h.DeleteHabit)
+		grp.POST("/:id/archive", h.ArchiveHabit)
+		grp.POST("/:id/unarchive", h.UnarchiveHabit)
+		grp.POST("/:id/restore", h.RestoreHabit)
+		grp.POST("/:id/freezes", h.AddFreeze)
+		grp.DELETE("/:id/freezes/:date", h.RemoveFreeze)
 	}
 }
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"habit-tracker-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeatmapHandler — публичные SVG тепловых карт для вики и README
+// и управление их секретным адресом
+type HeatmapHandler struct {
+	users    *service.UserService
+	checkins *service.HabitCheckinService
+}
+
+func NewHeatmapHandler(users *service.UserService, checkins *service.HabitCheckinService) *HeatmapHandler {
+	return &HeatmapHandler{users, checkins}
+}
+
+// Enable — POST /api/me/heatmap: новый секретный адрес карты (старый перестаёт работать)
+func (h *HeatmapHandler) Enable(c *gin.Context) {
+	url, err := h.users.EnableHeatmap(c.GetString("userEmail"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"url": url + ".svg", "habit_url": url + "/habits/{id}.svg"})
+}
+
+// Disable — DELETE /api/me/heatmap: закрывает публичную карту
+func (h *HeatmapHandler) Disable(c *gin.Context) {
+	if err := h.users.DisableHeatmap(c.GetString("userEmail")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "heatmap disabled"})
+}
+
+// UserSVG — GET /heatmap/:token.svg без авторизации: карта по всем привычкам
+func (h *HeatmapHandler) UserSVG(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".svg")
+	hm, err := h.checkins.PublicUserHeatmap(token, c.Query("from"), c.Query("to"))
+	writeHeatmapSVG(c, hm, err)
+}
+
+// HabitSVG — GET /heatmap/:token/habits/:id.svg без авторизации: карта одной привычки
+func (h *HeatmapHandler) HabitSVG(c *gin.Context) {
+	id := strings.TrimSuffix(c.Param("id"), ".svg")
+	hm, err := h.checkins.PublicHeatmap(c.Param("token"), id, c.Query("from"), c.Query("to"))
+	writeHeatmapSVG(c, hm, err)
+}
+
+func writeHeatmapSVG(c *gin.Context, hm *service.Heatmap, err error) {
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrHeatmapNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	var buf bytes.Buffer
+	if err := renderHeatmapSVG(&buf, hm); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "image/svg+xml", buf.Bytes())
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"habit-tracker-api/internal/service"
+)
+
+// Размеры и цвета SVG-календаря (как у GitHub: недели — столбцы, дни — строки с понедельника)
+const (
+	heatmapCell   = 10 // сторона клетки
+	heatmapGap    = 2  // зазор между клетками
+	heatmapLeft   = 28 // место под подписи дней недели
+	heatmapTop    = 16 // место под подписи месяцев
+	heatmapFrozen = "#c6e2f5"
+	heatmapRest   = "#f6f8fa" // в этот день ничего не требовалось
+)
+
+// heatmapLevels — цвета от «ничего» до «цель выполнена»
+var heatmapLevels = [...]string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"}
+
+// heatmapColor подбирает цвет клетки по доле выполнения
+func heatmapColor(d service.HeatmapDay) string {
+	switch {
+	case d.Value >= 1:
+		return heatmapLevels[4]
+	case d.Frozen:
+		return heatmapFrozen
+	case d.Rest && d.Value <= 0:
+		return heatmapRest
+	case d.Value <= 0:
+		return heatmapLevels[0]
+	case d.Value < 1.0/3:
+		return heatmapLevels[1]
+	case d.Value < 2.0/3:
+		return heatmapLevels[2]
+	}
+	return heatmapLevels[3]
+}
+
+// renderHeatmapSVG рисует тепловую карту; даты в Days — YYYY-MM-DD по возрастанию
+func renderHeatmapSVG(w io.Writer, hm *service.Heatmap) error {
+	step := heatmapCell + heatmapGap
+	first, err := time.Parse("2006-01-02", hm.From)
+	if err != nil {
+		return err
+	}
+	// первый столбец начинается с понедельника недели, в которую попал From
+	offset := (int(first.Weekday()) + 6) % 7
+	weeks := (offset + len(hm.Days) + 6) / 7
+	width := heatmapLeft + weeks*step
+	height := heatmapTop + 7*step
+
+	p := func(format string, args ...any) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+	p(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="9" fill="#767676">`+"\n",
+		width, height, width, height)
+	for row, name := range []string{0: "Mon", 2: "Wed", 4: "Fri"} {
+		if name != "" {
+			p(`<text x="0" y="%d">%s</text>`+"\n", heatmapTop+row*step+heatmapCell-1, name)
+		}
+	}
+	lastMonth := time.Month(0)
+	for i, d := range hm.Days {
+		day, perr := time.Parse("2006-01-02", d.Date)
+		if perr != nil {
+			return perr
+		}
+		col, row := (offset+i)/7, (offset+i)%7
+		x, y := heatmapLeft+col*step, heatmapTop+row*step
+		// подпись месяца над первой неделей, в которой он начался
+		if day.Month() != lastMonth && (row == 0 || i == 0) {
+			p(`<text x="%d" y="%d">%s</text>`+"\n", x, heatmapTop-6, day.Format("Jan"))
+			lastMonth = day.Month()
+		}
+		p(`<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="%s"><title>%s: %.0f%%</title></rect>`+"\n",
+			x, y, heatmapCell, heatmapCell, heatmapColor(d), d.Date, d.Value*100)
+	}
+	p("</svg>\n")
+	return err
+}
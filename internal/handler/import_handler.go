@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"habit-tracker-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImportHandler — импорт привычек из файлов других приложений
+type ImportHandler struct {
+	service *service.ImportService
+}
+
+func NewImportHandler(s *service.ImportService) *ImportHandler {
+	return &ImportHandler{s}
+}
+
+// Import — POST /import?format=loop|json&dry_run=true.
+// Файл передаётся полем file формы multipart или телом запроса целиком.
+func (h *ImportHandler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxImportSize)
+	data, err := readImportFile(c)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(data) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+	res, err := h.service.Import(c.GetString("userEmail"), data, c.Query("format"), dryRun)
+	if err != nil {
+		// 400 — только неверный файл; сбой хранилища — ошибка сервера
+		status := http.StatusInternalServerError
+		if isValidation(err) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if res.CreatedHabits > 0 && !dryRun {
+		status = http.StatusCreated
+	}
+	c.JSON(status, res)
+}
+
+func readImportFile(c *gin.Context) ([]byte, error) {
+	if c.ContentType() != "multipart/form-data" {
+		return io.ReadAll(c.Request.Body)
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -1,7 +1,6 @@
 package repository
 
 import (
-	"log"
 	"time"
 
 	bolt "go.etcd.io/bbolt"
@@ -9,22 +8,17 @@ import (
 
 const userBucket = "Users"
 
-var DB *bolt.DB
-
-// InitDB открывает или создаёт файл базы и инициализирует нужные «бадкеты»
-func InitDB() {
-	var err error
-	DB, err = bolt.Open("habit_tracker.db", 0600, &bolt.Options{Timeout: 1 * time.Second})
+// InitDB открывает или создаёт файл базы по пути path и применяет миграции схемы.
+// Базу, созданную более новой версией приложения, не открывает.
+func InitDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
 	if err != nil {
-		log.Fatalf("failed to open BoltDB: %v", err)
+		return nil, err
 	}
 
-	// Создадим бакет для хранения пользователей
-	err = DB.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(userBucket))
-		return err
-	})
-	if err != nil {
-		log.Fatalf("failed to create bucket: %v", err)
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
 	}
+	return db, nil
 }
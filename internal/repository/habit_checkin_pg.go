@@ -12,49 +12,179 @@ import (
 
 const checkinBucket = "habit_checkins"
 
-type HabitCheckinRepository struct{}
+type HabitCheckinRepository struct {
+	db *bolt.DB
+}
 
-func NewHabitCheckinRepository() *HabitCheckinRepository {
+func NewHabitCheckinRepository(db *bolt.DB) *HabitCheckinRepository {
 	// Создаём бакет, если надо
-	_ = DB.Update(func(tx *bolt.Tx) error {
+	_ = db.Update(func(tx *bolt.Tx) error {
 		_, err := tx.CreateBucketIfNotExists([]byte(checkinBucket))
 		return err
 	})
-	return &HabitCheckinRepository{}
+	return &HabitCheckinRepository{db: db}
 }
 
-// Create — добавляет новую запись о выполнении
+// Create — добавляет новую запись о выполнении.
+// Если за этот день отметка уже есть, количество суммируется с ней.
 func (r *HabitCheckinRepository) Create(hc *domain.HabitCheckin) error {
-	hc.ID = uuid.New().String()
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return createCheckin(tx.Bucket([]byte(checkinBucket)), hc)
+	})
+}
+
+// CreateMany — добавляет отметки одной транзакцией (по правилам Create)
+func (r *HabitCheckinRepository) CreateMany(hcs []domain.HabitCheckin) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(checkinBucket))
+		for i := range hcs {
+			if err := createCheckin(b, &hcs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func createCheckin(b *bolt.Bucket, hc *domain.HabitCheckin) error {
+	// ключ — сочетание habitID + дата(YYYY-MM-DD), чтобы избежать дубликатов
+	key := checkinKey(hc.HabitID, hc.Date.Format("2006-01-02"))
+	if v := b.Get(key); v != nil {
+		var prev domain.HabitCheckin
+		if err := json.Unmarshal(v, &prev); err != nil {
+			return err
+		}
+		hc.ID = prev.ID
+		hc.Date = prev.Date
+		hc.Amount += prev.AmountOrOne()
+		if hc.Comment == "" {
+			hc.Comment = prev.Comment
+		}
+	} else {
+		hc.ID = uuid.New().String()
+	}
 	data, err := json.Marshal(hc)
 	if err != nil {
 		return err
 	}
-	return DB.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(checkinBucket))
-		// ключ — сочетание habitID + дата(YYYY-MM-DD), чтобы избежать дубликатов
-		key := []byte(hc.HabitID + "|" + hc.Date.Format("2006-01-02"))
-		return b.Put(key, data)
-	})
+	return b.Put(key, data)
 }
 
 // FindByHabit — возвращает все check‑in’ы для данной привычки
 func (r *HabitCheckinRepository) FindByHabit(habitID string) ([]domain.HabitCheckin, error) {
 	var res []domain.HabitCheckin
-	err := DB.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(checkinBucket))
-		return b.ForEach(func(k, v []byte) error {
-			// ключ начинается с habitID|
-			if !bytes.HasPrefix(k, []byte(habitID+"|")) {
-				return nil
-			}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		// ключи отсортированы, отметки привычки лежат подряд с префиксом habitID|
+		prefix := []byte(habitID + "|")
+		c := tx.Bucket([]byte(checkinBucket)).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
 			var hc domain.HabitCheckin
 			if err := json.Unmarshal(v, &hc); err != nil {
 				return err
 			}
 			res = append(res, hc)
-			return nil
-		})
+		}
+		return nil
 	})
 	return res, err
 }
+
+// FindByHabits — отметки нескольких привычек в одной read-транзакции
+func (r *HabitCheckinRepository) FindByHabits(habitIDs []string) (map[string][]domain.HabitCheckin, error) {
+	res := make(map[string][]domain.HabitCheckin, len(habitIDs))
+	err := r.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(checkinBucket)).Cursor()
+		for _, id := range habitIDs {
+			prefix := []byte(id + "|")
+			for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+				var hc domain.HabitCheckin
+				if err := json.Unmarshal(v, &hc); err != nil {
+					return err
+				}
+				res[id] = append(res[id], hc)
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+// deleteHabitCheckins удаляет все отметки привычки внутри транзакции
+func deleteHabitCheckins(tx *bolt.Tx, habitID string) error {
+	b := tx.Bucket([]byte(checkinBucket))
+	if b == nil {
+		return nil
+	}
+	prefix := []byte(habitID + "|")
+	c := b.Cursor()
+	// Cursor.Delete сдвигает курсор на следующую запись, поэтому берём текущую через Seek
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Seek(prefix) {
+		if err := c.Delete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForEach — обходит все отметки в порядке ключей (habitID|YYYY-MM-DD)
+func (r *HabitCheckinRepository) ForEach(fn func(hc *domain.HabitCheckin) error) error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(checkinBucket)).ForEach(func(_, v []byte) error {
+			var hc domain.HabitCheckin
+			if err := json.Unmarshal(v, &hc); err != nil {
+				return err
+			}
+			return fn(&hc)
+		})
+	})
+}
+
+// checkinKey — ключ записи: habitID|YYYY-MM-DD
+func checkinKey(habitID, day string) []byte {
+	return []byte(habitID + "|" + day)
+}
+
+// FindByHabitAndDate — возвращает отметку привычки за день (YYYY-MM-DD)
+func (r *HabitCheckinRepository) FindByHabitAndDate(habitID, day string) (*domain.HabitCheckin, error) {
+	var hc domain.HabitCheckin
+	err := r.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(checkinBucket))
+		v := b.Get(checkinKey(habitID, day))
+		if v == nil {
+			return domain.ErrCheckinNotFound
+		}
+		return json.Unmarshal(v, &hc)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hc, nil
+}
+
+// Update — перезаписывает существующую отметку (без суммирования)
+func (r *HabitCheckinRepository) Update(hc *domain.HabitCheckin) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(checkinBucket))
+		key := checkinKey(hc.HabitID, hc.Date.Format("2006-01-02"))
+		if b.Get(key) == nil {
+			return domain.ErrCheckinNotFound
+		}
+		data, err := json.Marshal(hc)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, data)
+	})
+}
+
+// Delete — удаляет отметку привычки за день (YYYY-MM-DD)
+func (r *HabitCheckinRepository) Delete(habitID, day string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(checkinBucket))
+		key := checkinKey(habitID, day)
+		if b.Get(key) == nil {
+			return domain.ErrCheckinNotFound
+		}
+		return b.Delete(key)
+	})
+}
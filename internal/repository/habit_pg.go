@@ -2,9 +2,6 @@ package repository
 
 import (
 	"encoding/json"
-	"errors"
-	"sort"
-	"strings"
 	"time"
 
 	"habit-tracker-api/internal/domain"
@@ -13,18 +10,77 @@ import (
 	bolt "go.etcd.io/bbolt"
 )
 
-const habitBucket = "habits"
+const (
+	habitBucket = "habits"
+
+	// Индекс привычек по пользователю, обновляется в той же транзакции, что и записи:
+	// вложенный бакет на пользователя: ID привычки -> пусто
+	habitsByUserBucket = "habits_by_user"
+)
 
 // HabitRepository работает поверх BoltDB
-type HabitRepository struct{}
+type HabitRepository struct {
+	db *bolt.DB
+}
 
 // NewHabitRepository — конструктор, создает bucket, если нужно
-func NewHabitRepository() *HabitRepository {
-	_ = DB.Update(func(tx *bolt.Tx) error {
+// (индексы для старых баз строит миграция в InitDB)
+func NewHabitRepository(db *bolt.DB) *HabitRepository {
+	_ = db.Update(func(tx *bolt.Tx) error {
 		_, err := tx.CreateBucketIfNotExists([]byte(habitBucket))
 		return err
 	})
-	return &HabitRepository{}
+	return &HabitRepository{db: db}
+}
+
+func indexHabit(tx *bolt.Tx, h *domain.Habit) error {
+	byUser, err := tx.Bucket([]byte(habitsByUserBucket)).CreateBucketIfNotExists([]byte(h.UserEmail))
+	if err != nil {
+		return err
+	}
+	return byUser.Put([]byte(h.ID), nil)
+}
+
+func unindexHabit(tx *bolt.Tx, h *domain.Habit) error {
+	if byUser := tx.Bucket([]byte(habitsByUserBucket)).Bucket([]byte(h.UserEmail)); byUser != nil {
+		return byUser.Delete([]byte(h.ID))
+	}
+	return nil
+}
+
+// putHabit записывает привычку и обновляет индексы (старые записи индекса удаляются)
+func putHabit(tx *bolt.Tx, h *domain.Habit) error {
+	b := tx.Bucket([]byte(habitBucket))
+	if v := b.Get([]byte(h.ID)); v != nil {
+		var old domain.Habit
+		if err := json.Unmarshal(v, &old); err != nil {
+			return err
+		}
+		if err := unindexHabit(tx, &old); err != nil {
+			return err
+		}
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := b.Put([]byte(h.ID), data); err != nil {
+		return err
+	}
+	return indexHabit(tx, h)
+}
+
+// getHabit читает привычку внутри транзакции
+func getHabit(tx *bolt.Tx, id string) (*domain.Habit, error) {
+	v := tx.Bucket([]byte(habitBucket)).Get([]byte(id))
+	if v == nil {
+		return nil, domain.ErrHabitNotFound
+	}
+	var h domain.Habit
+	if err := json.Unmarshal(v, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
 }
 
 // Create — сохраняет новую привычку в BoltDB
@@ -35,29 +91,25 @@ func (r *HabitRepository) Create(h *domain.Habit) error {
 	if h.CreatedAt.IsZero() {
 		h.CreatedAt = time.Now()
 	}
-	return DB.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(habitBucket))
-		data, err := json.Marshal(h)
-		if err != nil {
-			return err
-		}
-		return b.Put([]byte(h.ID), data)
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return putHabit(tx, h)
 	})
 }
 
-// FindAllByUser — возвращает все привычки заданного пользователя
+// FindAllByUser — возвращает все привычки заданного пользователя (через индекс)
 func (r *HabitRepository) FindAllByUser(email string) ([]*domain.Habit, error) {
 	var habits []*domain.Habit
-	err := DB.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(habitBucket))
-		return b.ForEach(func(_, v []byte) error {
-			var h domain.Habit
-			if err := json.Unmarshal(v, &h); err != nil {
+	err := r.db.View(func(tx *bolt.Tx) error {
+		byUser := tx.Bucket([]byte(habitsByUserBucket)).Bucket([]byte(email))
+		if byUser == nil {
+			return nil
+		}
+		return byUser.ForEach(func(id, _ []byte) error {
+			h, err := getHabit(tx, string(id))
+			if err != nil {
 				return err
 			}
-			if h.UserEmail == email {
-				habits = append(habits, &h)
-			}
+			habits = append(habits, h)
 			return nil
 		})
 	})
@@ -66,104 +118,54 @@ func (r *HabitRepository) FindAllByUser(email string) ([]*domain.Habit, error) {
 
 // FindByID — возвращает привычку по её ID
 func (r *HabitRepository) FindByID(id string) (*domain.Habit, error) {
-	var h domain.Habit
-	err := DB.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(habitBucket))
-		v := b.Get([]byte(id))
-		if v == nil {
-			return errors.New("habit not found")
-		}
-		return json.Unmarshal(v, &h)
+	var h *domain.Habit
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		h, err = getHabit(tx, id)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &h, nil
+	return h, nil
 }
 
 // Update — обновляет существующую привычку
 func (r *HabitRepository) Update(h *domain.Habit) error {
-	return DB.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(habitBucket))
-		if b.Get([]byte(h.ID)) == nil {
-			return errors.New("habit not found")
+	return r.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte(habitBucket)).Get([]byte(h.ID)) == nil {
+			return domain.ErrHabitNotFound
 		}
-		data, err := json.Marshal(h)
-		if err != nil {
-			return err
-		}
-		return b.Put([]byte(h.ID), data)
+		return putHabit(tx, h)
 	})
 }
 
-// Delete — удаляет привычку по ID
+// Delete — удаляет привычку по ID вместе со всеми её отметками (в одной транзакции)
 func (r *HabitRepository) Delete(id string) error {
-	return DB.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(habitBucket))
-		if b.Get([]byte(id)) == nil {
-			return errors.New("habit not found")
+	return r.db.Update(func(tx *bolt.Tx) error {
+		h, err := getHabit(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := unindexHabit(tx, h); err != nil {
+			return err
 		}
-		return b.Delete([]byte(id))
+		if err := deleteHabitCheckins(tx, id); err != nil {
+			return err
+		}
+		return tx.Bucket([]byte(habitBucket)).Delete([]byte(id))
 	})
 }
 
-// FindAll — возвращает все привычки с фильтрацией и пагинацией
-func (r *HabitRepository) FindAll(
-	name string,
-	dateFrom, dateTo *time.Time,
-	offset, limit int,
-) ([]*domain.Habit, error) {
-	var all []*domain.Habit
-
-	// Сначала читаем всё
-	err := DB.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(habitBucket))
-		return b.ForEach(func(_, v []byte) error {
+// ForEach — обходит все привычки в порядке ключей (ID)
+func (r *HabitRepository) ForEach(fn func(h *domain.Habit) error) error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(habitBucket)).ForEach(func(_, v []byte) error {
 			var h domain.Habit
 			if err := json.Unmarshal(v, &h); err != nil {
 				return err
 			}
-			// Фильтрация по имени
-			if name != "" && !containsIgnoreCase(h.Name, name) {
-				return nil
-			}
-			// Фильтрация по диапазону дат
-			if dateFrom != nil && h.CreatedAt.Before(*dateFrom) {
-				return nil
-			}
-			if dateTo != nil && h.CreatedAt.After(*dateTo) {
-				return nil
-			}
-			all = append(all, &h)
-			return nil
+			return fn(&h)
 		})
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	// Сортируем по CreatedAt (последние первыми)
-	sort.Slice(all, func(i, j int) bool {
-		return all[i].CreatedAt.After(all[j].CreatedAt)
-	})
-
-	// Пагинация
-	start := offset
-	if start > len(all) {
-		start = len(all)
-	}
-	end := offset + limit
-	if end > len(all) {
-		end = len(all)
-	}
-
-	return all[start:end], nil
-}
-
-// containsIgnoreCase проверяет вхождение подстроки без учета регистра
-func containsIgnoreCase(s, substr string) bool {
-	return strings.Contains(
-		strings.ToLower(s),
-		strings.ToLower(substr),
-	)
 }
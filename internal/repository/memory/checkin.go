@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"habit-tracker-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CheckinStore хранит отметки в map по ключу habitID|YYYY-MM-DD
+type CheckinStore struct {
+	mu       sync.RWMutex
+	checkins map[string]domain.HabitCheckin
+}
+
+func NewCheckinStore() *CheckinStore {
+	return &CheckinStore{checkins: make(map[string]domain.HabitCheckin)}
+}
+
+func checkinKey(habitID, day string) string {
+	return habitID + "|" + day
+}
+
+// Create — добавляет отметку или суммирует количество с отметкой за тот же день
+func (s *CheckinStore) Create(hc *domain.HabitCheckin) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.create(hc)
+	return nil
+}
+
+// CreateMany — добавляет отметки под одной блокировкой (по правилам Create)
+func (s *CheckinStore) CreateMany(hcs []domain.HabitCheckin) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range hcs {
+		s.create(&hcs[i])
+	}
+	return nil
+}
+
+func (s *CheckinStore) create(hc *domain.HabitCheckin) {
+	key := checkinKey(hc.HabitID, hc.Date.Format("2006-01-02"))
+	if prev, ok := s.checkins[key]; ok {
+		hc.ID = prev.ID
+		hc.Date = prev.Date
+		hc.Amount += prev.AmountOrOne()
+		if hc.Comment == "" {
+			hc.Comment = prev.Comment
+		}
+	} else {
+		hc.ID = uuid.New().String()
+	}
+	s.checkins[key] = *hc
+}
+
+func (s *CheckinStore) FindByHabit(habitID string) ([]domain.HabitCheckin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []domain.HabitCheckin
+	for _, hc := range s.checkins {
+		if hc.HabitID == habitID {
+			res = append(res, hc)
+		}
+	}
+	return res, nil
+}
+
+func (s *CheckinStore) FindByHabits(habitIDs []string) (map[string][]domain.HabitCheckin, error) {
+	want := make(map[string]bool, len(habitIDs))
+	for _, id := range habitIDs {
+		want[id] = true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	res := make(map[string][]domain.HabitCheckin, len(habitIDs))
+	for _, hc := range s.checkins {
+		if want[hc.HabitID] {
+			res[hc.HabitID] = append(res[hc.HabitID], hc)
+		}
+	}
+	return res, nil
+}
+
+func (s *CheckinStore) FindByHabitAndDate(habitID, day string) (*domain.HabitCheckin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hc, ok := s.checkins[checkinKey(habitID, day)]
+	if !ok {
+		return nil, domain.ErrCheckinNotFound
+	}
+	return &hc, nil
+}
+
+func (s *CheckinStore) Update(hc *domain.HabitCheckin) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := checkinKey(hc.HabitID, hc.Date.Format("2006-01-02"))
+	if _, ok := s.checkins[key]; !ok {
+		return domain.ErrCheckinNotFound
+	}
+	s.checkins[key] = *hc
+	return nil
+}
+
+func (s *CheckinStore) Delete(habitID, day string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := checkinKey(habitID, day)
+	if _, ok := s.checkins[key]; !ok {
+		return domain.ErrCheckinNotFound
+	}
+	delete(s.checkins, key)
+	return nil
+}
+
+// ForEach — обходит отметки по возрастанию ключа habitID|YYYY-MM-DD
+func (s *CheckinStore) ForEach(fn func(hc *domain.HabitCheckin) error) error {
+	s.mu.RLock()
+	keys := make([]string, 0, len(s.checkins))
+	for k := range s.checkins {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	all := make([]domain.HabitCheckin, len(keys))
+	for i, k := range keys {
+		all[i] = s.checkins[k]
+	}
+	s.mu.RUnlock()
+	for i := range all {
+		if err := fn(&all[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteHabit удаляет все отметки привычки
+func (s *CheckinStore) deleteHabit(habitID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, hc := range s.checkins {
+		if hc.HabitID == habitID {
+			delete(s.checkins, key)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+func TestCheckinStoreCreateMergesDay(t *testing.T) {
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		first  float64 // количество первой записи за день (0 — запись без количества)
+		second float64
+		want   float64
+	}{
+		{"amounts add up", 2, 3, 5},
+		{"legacy record counts as one", 0, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewCheckinStore()
+			if err := s.Create(&domain.HabitCheckin{HabitID: "h", Date: day, Amount: 1}); err != nil {
+				t.Fatal(err)
+			}
+			// первую запись сохраняем как есть, в обход суммирования
+			if err := s.Update(&domain.HabitCheckin{HabitID: "h", Date: day, Amount: tt.first}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Create(&domain.HabitCheckin{HabitID: "h", Date: day.Add(3 * time.Hour), Amount: tt.second}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.FindByHabitAndDate("h", "2026-10-15")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("Amount = %v, want %v", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckinStoreNotFound(t *testing.T) {
+	s := NewCheckinStore()
+	if _, err := s.FindByHabitAndDate("h", "2026-10-15"); !errors.Is(err, domain.ErrCheckinNotFound) {
+		t.Errorf("FindByHabitAndDate: err = %v", err)
+	}
+	if err := s.Delete("h", "2026-10-15"); !errors.Is(err, domain.ErrCheckinNotFound) {
+		t.Errorf("Delete: err = %v", err)
+	}
+}
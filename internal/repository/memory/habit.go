@@ -0,0 +1,118 @@
+// Package memory — хранилища в памяти процесса: для тестов и локальных
+// экспериментов, данные пропадают при остановке.
+package memory
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// HabitStore хранит привычки в map; при удалении привычки
+// удаляет и её отметки из checkins
+type HabitStore struct {
+	mu       sync.RWMutex
+	habits   map[string]domain.Habit
+	checkins *CheckinStore
+}
+
+func NewHabitStore(checkins *CheckinStore) *HabitStore {
+	return &HabitStore{habits: make(map[string]domain.Habit), checkins: checkins}
+}
+
+func (s *HabitStore) Create(h *domain.Habit) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = time.Now()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.habits[h.ID] = cloneHabit(h)
+	return nil
+}
+
+func (s *HabitStore) FindAllByUser(email string) ([]*domain.Habit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var res []*domain.Habit
+	for _, h := range s.habits {
+		if h.UserEmail == email {
+			h := cloneHabit(&h)
+			res = append(res, &h)
+		}
+	}
+	return res, nil
+}
+
+func (s *HabitStore) FindByID(id string) (*domain.Habit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	h, ok := s.habits[id]
+	if !ok {
+		return nil, domain.ErrHabitNotFound
+	}
+	h = cloneHabit(&h)
+	return &h, nil
+}
+
+func (s *HabitStore) Update(h *domain.Habit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.habits[h.ID]; !ok {
+		return domain.ErrHabitNotFound
+	}
+	s.habits[h.ID] = cloneHabit(h)
+	return nil
+}
+
+func (s *HabitStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.habits[id]; !ok {
+		return domain.ErrHabitNotFound
+	}
+	delete(s.habits, id)
+	s.checkins.deleteHabit(id)
+	return nil
+}
+
+// ForEach — обходит привычки по возрастанию ID (по снимку на момент вызова)
+func (s *HabitStore) ForEach(fn func(h *domain.Habit) error) error {
+	s.mu.RLock()
+	all := make([]domain.Habit, 0, len(s.habits))
+	for _, h := range s.habits {
+		all = append(all, cloneHabit(&h))
+	}
+	s.mu.RUnlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	for i := range all {
+		if err := fn(&all[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneHabit копирует привычку вместе со срезами и указателями: иначе правки
+// загруженной привычки (например, дней отдыха) попали бы в хранилище до Update
+func cloneHabit(h *domain.Habit) domain.Habit {
+	c := *h
+	c.Schedule.Weekdays = slices.Clone(h.Schedule.Weekdays)
+	c.FreezeDays = slices.Clone(h.FreezeDays)
+	if h.ArchivedAt != nil {
+		t := *h.ArchivedAt
+		c.ArchivedAt = &t
+	}
+	if h.DeletedAt != nil {
+		t := *h.DeletedAt
+		c.DeletedAt = &t
+	}
+	return c
+}
@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// Правки загруженной привычки не должны попадать в хранилище до Update
+func TestHabitStoreReturnsCopies(t *testing.T) {
+	s := NewHabitStore(NewCheckinStore())
+	archived := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	h := &domain.Habit{
+		UserEmail:  "a@example.com",
+		Name:       "Read",
+		Schedule:   domain.Schedule{Type: domain.ScheduleWeekdays, Weekdays: []time.Weekday{time.Monday}},
+		ArchivedAt: &archived,
+		FreezeDays: []string{"2026-10-15", "2026-10-16", "2026-10-17"},
+	}
+	if err := s.Create(h); err != nil {
+		t.Fatal(err)
+	}
+	// исходный объект после Create тоже не связан с хранилищем
+	h.FreezeDays[0] = "changed"
+
+	mutate := func(h *domain.Habit) {
+		h.FreezeDays = append(h.FreezeDays[:0], h.FreezeDays[1:]...)
+		h.Schedule.Weekdays[0] = time.Friday
+		*h.ArchivedAt = archived.AddDate(1, 0, 0)
+	}
+	got, err := s.FindByID(h.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mutate(got)
+	all, err := s.FindAllByUser("a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mutate(all[0])
+	err = s.ForEach(func(h *domain.Habit) error {
+		mutate(h)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := s.FindByID(h.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2026-10-15", "2026-10-16", "2026-10-17"}; !slices.Equal(stored.FreezeDays, want) {
+		t.Errorf("FreezeDays = %v, want %v", stored.FreezeDays, want)
+	}
+	if stored.Schedule.Weekdays[0] != time.Monday {
+		t.Errorf("Weekdays = %v, want [Monday]", stored.Schedule.Weekdays)
+	}
+	if !stored.ArchivedAt.Equal(archived) {
+		t.Errorf("ArchivedAt = %v, want %v", stored.ArchivedAt, archived)
+	}
+}
+
+// После Update хранилище тоже не делит срезы с переданной привычкой
+func TestHabitStoreUpdateCopies(t *testing.T) {
+	s := NewHabitStore(NewCheckinStore())
+	h := &domain.Habit{UserEmail: "a@example.com", Name: "Read"}
+	if err := s.Create(h); err != nil {
+		t.Fatal(err)
+	}
+	h.FreezeDays = []string{"2026-10-15"}
+	if err := s.Update(h); err != nil {
+		t.Fatal(err)
+	}
+	h.FreezeDays[0] = "changed"
+
+	stored, err := s.FindByID(h.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2026-10-15"}; !slices.Equal(stored.FreezeDays, want) {
+		t.Errorf("FreezeDays = %v, want %v", stored.FreezeDays, want)
+	}
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// TokenStore хранит refresh-токены, отзывы и одноразовые токены в map
+type TokenStore struct {
+	mu            sync.Mutex
+	refresh       map[string]domain.RefreshToken
+	revoked       map[string]time.Time
+	revokedBefore map[string]time.Time
+	userTokens    map[string]domain.UserToken
+}
+
+func NewTokenStore() *TokenStore {
+	return &TokenStore{
+		refresh:       make(map[string]domain.RefreshToken),
+		revoked:       make(map[string]time.Time),
+		revokedBefore: make(map[string]time.Time),
+		userTokens:    make(map[string]domain.UserToken),
+	}
+}
+
+func (s *TokenStore) SaveRefreshToken(rt *domain.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh[rt.Hash] = *rt
+	return nil
+}
+
+func (s *TokenStore) ConsumeRefreshToken(hash string) (*domain.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rt, ok := s.refresh[hash]
+	if !ok {
+		return nil, errors.New("refresh token not found")
+	}
+	delete(s.refresh, hash)
+	return &rt, nil
+}
+
+func (s *TokenStore) DeleteRefreshToken(email, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rt, ok := s.refresh[hash]; ok && rt.UserEmail == email {
+		delete(s.refresh, hash)
+	}
+	return nil
+}
+
+func (s *TokenStore) RevokeAll(email string, since time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for hash, rt := range s.refresh {
+		if rt.UserEmail == email {
+			delete(s.refresh, hash)
+		}
+	}
+	s.revokedBefore[email] = since
+	return nil
+}
+
+func (s *TokenStore) RevokedBefore(email string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.revokedBefore[email], nil
+}
+
+func (s *TokenStore) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, exp := range s.revoked {
+		if exp.Before(now) {
+			delete(s.revoked, id)
+		}
+	}
+	s.revoked[tokenID] = expiresAt
+	return nil
+}
+
+func (s *TokenStore) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.revoked[tokenID]
+	return ok, nil
+}
+
+func (s *TokenStore) SaveUserToken(ut *domain.UserToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.userTokens[ut.Hash] = *ut
+	return nil
+}
+
+func (s *TokenStore) ConsumeUserToken(hash, purpose string) (*domain.UserToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ut, ok := s.userTokens[hash]
+	if !ok || ut.Purpose != purpose {
+		return nil, errors.New("token not found")
+	}
+	delete(s.userTokens, hash)
+	return &ut, nil
+}
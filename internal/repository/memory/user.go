@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// UserStore хранит пользователей в map по email
+type UserStore struct {
+	mu    sync.RWMutex
+	users map[string]domain.User
+}
+
+func NewUserStore() *UserStore {
+	return &UserStore{users: make(map[string]domain.User)}
+}
+
+func (s *UserStore) Create(user *domain.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[user.Email]; ok {
+		return errors.New("user already exists")
+	}
+	s.users[user.Email] = *user
+	return nil
+}
+
+func (s *UserStore) FindByEmail(email string) (*domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.users[email]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return &u, nil
+}
+
+// FindByCalendarToken — перебор: в памяти пользователей немного
+func (s *UserStore) FindByCalendarToken(hash string) (*domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.users {
+		if hash != "" && u.CalendarToken == hash {
+			return &u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+// FindByHeatmapToken — перебор, как и для календаря
+func (s *UserStore) FindByHeatmapToken(hash string) (*domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.users {
+		if hash != "" && u.HeatmapToken == hash {
+			return &u, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (s *UserStore) Update(user *domain.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[user.Email]; !ok {
+		return errors.New("user not found")
+	}
+	s.users[user.Email] = *user
+	return nil
+}
+
+// ForEach — обходит пользователей по возрастанию email
+func (s *UserStore) ForEach(fn func(u *domain.User) error) error {
+	s.mu.RLock()
+	all := make([]domain.User, 0, len(s.users))
+	for _, u := range s.users {
+		all = append(all, u)
+	}
+	s.mu.RUnlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].Email < all[j].Email })
+	for i := range all {
+		if err := fn(&all[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
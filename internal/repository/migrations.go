@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Версия схемы хранится в бакете meta под ключом schema_version
+const (
+	metaBucket       = "meta"
+	schemaVersionKey = "schema_version"
+)
+
+// migration — шаг обновления данных. Шаг должен быть идемпотентным:
+// если запись уже в новом виде, он её не меняет.
+type migration struct {
+	name string
+	up   func(tx *bolt.Tx) error
+}
+
+// migrations — шаги по порядку; номер версии = индекс + 1.
+// Новые шаги только дописываются в конец.
+var migrations = []migration{
+	{"base buckets", createBaseBuckets},
+	{"user json field names", migrateUserFields},
+	{"habit indexes", reindexHabits},
+	{"default schedules and amounts", normalizeHabitData},
+	{"calendar token index", indexCalendarTokens},
+	{"drop habits by created index", dropHabitsByCreated},
+	{"heatmap token index", indexHeatmapTokens},
+}
+
+// migrate применяет недостающие миграции, каждую в своей транзакции
+func migrate(db *bolt.DB) error {
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than supported %d", current, len(migrations))
+	}
+	for v := current + 1; v <= len(migrations); v++ {
+		m := migrations[v-1]
+		err := db.Update(func(tx *bolt.Tx) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			meta, err := tx.CreateBucketIfNotExists([]byte(metaBucket))
+			if err != nil {
+				return err
+			}
+			return meta.Put([]byte(schemaVersionKey), []byte(strconv.Itoa(v)))
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", v, m.name, err)
+		}
+	}
+	return nil
+}
+
+// schemaVersion читает версию схемы; у баз без бакета meta она 0
+func schemaVersion(db *bolt.DB) (int, error) {
+	var v int
+	err := db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket([]byte(metaBucket))
+		if meta == nil {
+			return nil
+		}
+		raw := meta.Get([]byte(schemaVersionKey))
+		if raw == nil {
+			return nil
+		}
+		var err error
+		if v, err = strconv.Atoi(string(raw)); err != nil {
+			return fmt.Errorf("invalid schema version %q", raw)
+		}
+		return nil
+	})
+	return v, err
+}
+
+// 1: бакеты пользователей, привычек и отметок
+func createBaseBuckets(tx *bolt.Tx) error {
+	for _, name := range []string{userBucket, habitBucket, checkinBucket} {
+		if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyUser читает пользователей, сохранённых до появления json-тегов:
+// остальные поля совпадают без учёта регистра, а CreatedAt — нет
+type legacyUser struct {
+	domain.User
+	LegacyCreatedAt time.Time `json:"CreatedAt"`
+}
+
+// 2: пользователи переходят на имена полей из json-тегов domain.User
+func migrateUserFields(tx *bolt.Tx) error {
+	b := tx.Bucket([]byte(userBucket))
+	updates := map[string][]byte{}
+	err := b.ForEach(func(k, v []byte) error {
+		var lu legacyUser
+		if err := json.Unmarshal(v, &lu); err != nil {
+			return fmt.Errorf("user %s: %w", k, err)
+		}
+		if lu.CreatedAt.IsZero() {
+			lu.CreatedAt = lu.LegacyCreatedAt
+		}
+		data, err := json.Marshal(&lu.User)
+		if err != nil {
+			return err
+		}
+		updates[string(k)] = data
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// менять бакет во время ForEach нельзя, поэтому пишем после обхода
+	for k, data := range updates {
+		if err := b.Put([]byte(k), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// habitsByCreatedBucket — индекс "created_at(UTC)|ID" -> пусто. Его строила
+// миграция 3, а удаляет миграция 6: больше его никто не ведёт
+const habitsByCreatedBucket = "habits_by_created"
+
+// 3: индексы привычек по пользователю и по дате создания. Шаг уже применён
+// в существующих базах, поэтому записан как есть и не зависит от indexHabit
+func reindexHabits(tx *bolt.Tx) error {
+	for _, name := range []string{habitsByUserBucket, habitsByCreatedBucket} {
+		if tx.Bucket([]byte(name)) != nil {
+			if err := tx.DeleteBucket([]byte(name)); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.CreateBucket([]byte(name)); err != nil {
+			return err
+		}
+	}
+	byCreated := tx.Bucket([]byte(habitsByCreatedBucket))
+	return tx.Bucket([]byte(habitBucket)).ForEach(func(_, v []byte) error {
+		var h domain.Habit
+		if err := json.Unmarshal(v, &h); err != nil {
+			return err
+		}
+		byUser, err := tx.Bucket([]byte(habitsByUserBucket)).CreateBucketIfNotExists([]byte(h.UserEmail))
+		if err != nil {
+			return err
+		}
+		if err := byUser.Put([]byte(h.ID), nil); err != nil {
+			return err
+		}
+		key := h.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000000Z") + "|" + h.ID
+		return byCreated.Put([]byte(key), nil)
+	})
+}
+
+// 4: у старых привычек нет расписания — они ежедневные;
+// у старых отметок нет количества — это одна отметка
+func normalizeHabitData(tx *bolt.Tx) error {
+	habits := tx.Bucket([]byte(habitBucket))
+	var fixed []*domain.Habit
+	err := habits.ForEach(func(_, v []byte) error {
+		var h domain.Habit
+		if err := json.Unmarshal(v, &h); err != nil {
+			return err
+		}
+		if h.Schedule.Type == "" {
+			h.Schedule.Type = domain.ScheduleDaily
+			fixed = append(fixed, &h)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, h := range fixed {
+		if err := putHabit(tx, h); err != nil {
+			return err
+		}
+	}
+
+	checkins := tx.Bucket([]byte(checkinBucket))
+	updates := map[string][]byte{}
+	err = checkins.ForEach(func(k, v []byte) error {
+		var hc domain.HabitCheckin
+		if err := json.Unmarshal(v, &hc); err != nil {
+			return err
+		}
+		if hc.Amount > 0 {
+			return nil
+		}
+		hc.Amount = 1
+		data, err := json.Marshal(&hc)
+		if err != nil {
+			return err
+		}
+		updates[string(k)] = data
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for k, data := range updates {
+		if err := checkins.Put([]byte(k), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 5: индекс пользователей по токену ленты календаря
+func indexCalendarTokens(tx *bolt.Tx) error {
+	idx, err := tx.CreateBucketIfNotExists([]byte(usersByCalendarBucket))
+	if err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(userBucket)).ForEach(func(k, v []byte) error {
+		var u domain.User
+		if err := json.Unmarshal(v, &u); err != nil {
+			return fmt.Errorf("user %s: %w", k, err)
+		}
+		if u.CalendarToken == "" {
+			return nil
+		}
+		return idx.Put([]byte(u.CalendarToken), k)
+	})
+}
+
+// 6: индекс привычек по дате создания никто не читал,
+// и запись привычек его больше не обновляет
+func dropHabitsByCreated(tx *bolt.Tx) error {
+	if tx.Bucket([]byte(habitsByCreatedBucket)) == nil {
+		return nil
+	}
+	return tx.DeleteBucket([]byte(habitsByCreatedBucket))
+}
+
+// 7: индекс пользователей по токену публичной тепловой карты
+func indexHeatmapTokens(tx *bolt.Tx) error {
+	idx, err := tx.CreateBucketIfNotExists([]byte(usersByHeatmapBucket))
+	if err != nil {
+		return err
+	}
+	return tx.Bucket([]byte(userBucket)).ForEach(func(k, v []byte) error {
+		var u domain.User
+		if err := json.Unmarshal(v, &u); err != nil {
+			return fmt.Errorf("user %s: %w", k, err)
+		}
+		if u.HeatmapToken == "" {
+			return nil
+		}
+		return idx.Put([]byte(u.HeatmapToken), k)
+	})
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"habit-tracker-api/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func openRawDB(t *testing.T) *bolt.DB {
+	t.Helper()
+	db, err := bolt.Open(filepath.Join(t.TempDir(), "habits.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// applySteps применяет миграции с номерами от from до to включительно
+func applySteps(t *testing.T, db *bolt.DB, from, to int) {
+	t.Helper()
+	for v := from; v <= to; v++ {
+		if err := db.Update(migrations[v-1].up); err != nil {
+			t.Fatalf("migration %d: %v", v, err)
+		}
+	}
+}
+
+func put(t *testing.T, tx *bolt.Tx, bucket, key, value string) {
+	t.Helper()
+	if err := tx.Bucket([]byte(bucket)).Put([]byte(key), []byte(value)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func get(tx *bolt.Tx, bucket, key string) []byte {
+	b := tx.Bucket([]byte(bucket))
+	if b == nil {
+		return nil
+	}
+	return b.Get([]byte(key))
+}
+
+// Каждый шаг переводит данные предыдущей версии в новый вид
+// и не меняет их при повторном запуске
+func TestMigrationSteps(t *testing.T) {
+	tests := []struct {
+		step  int
+		seed  func(t *testing.T, tx *bolt.Tx) // данные версии step-1
+		check func(t *testing.T, tx *bolt.Tx)
+	}{
+		{
+			step: 1,
+			check: func(t *testing.T, tx *bolt.Tx) {
+				for _, name := range []string{userBucket, habitBucket, checkinBucket} {
+					if tx.Bucket([]byte(name)) == nil {
+						t.Errorf("bucket %s is missing", name)
+					}
+				}
+			},
+		},
+		{
+			step: 2,
+			seed: func(t *testing.T, tx *bolt.Tx) {
+				put(t, tx, userBucket, "a@example.com",
+					`{"Email":"a@example.com","Password":"hash","CreatedAt":"2025-01-02T03:04:05Z"}`)
+			},
+			check: func(t *testing.T, tx *bolt.Tx) {
+				var u domain.User
+				if err := json.Unmarshal(get(tx, userBucket, "a@example.com"), &u); err != nil {
+					t.Fatal(err)
+				}
+				if u.Password != "hash" || u.CreatedAt.Format("2006-01-02") != "2025-01-02" {
+					t.Errorf("user = %+v", u)
+				}
+			},
+		},
+		{
+			step: 3,
+			seed: func(t *testing.T, tx *bolt.Tx) {
+				put(t, tx, habitBucket, "h1",
+					`{"id":"h1","user_email":"a@example.com","name":"Read","created_at":"2025-01-02T06:04:05+03:00"}`)
+			},
+			check: func(t *testing.T, tx *bolt.Tx) {
+				byUser := tx.Bucket([]byte(habitsByUserBucket)).Bucket([]byte("a@example.com"))
+				if byUser == nil || byUser.Get([]byte("h1")) == nil {
+					t.Error("habit is not indexed by user")
+				}
+				key := "2025-01-02T03:04:05.000000000Z|h1"
+				if tx.Bucket([]byte(habitsByCreatedBucket)).Get([]byte(key)) == nil {
+					t.Errorf("habit is not indexed by creation time as %s", key)
+				}
+			},
+		},
+		{
+			step: 4,
+			seed: func(t *testing.T, tx *bolt.Tx) {
+				put(t, tx, habitBucket, "h1",
+					`{"id":"h1","user_email":"a@example.com","name":"Read","created_at":"2025-01-02T03:04:05Z"}`)
+				put(t, tx, checkinBucket, "h1|2025-01-03",
+					`{"id":"c1","habit_id":"h1","date":"2025-01-03T00:00:00Z"}`)
+			},
+			check: func(t *testing.T, tx *bolt.Tx) {
+				var h domain.Habit
+				if err := json.Unmarshal(get(tx, habitBucket, "h1"), &h); err != nil {
+					t.Fatal(err)
+				}
+				if h.Schedule.Type != domain.ScheduleDaily {
+					t.Errorf("schedule = %+v, want daily", h.Schedule)
+				}
+				var hc domain.HabitCheckin
+				if err := json.Unmarshal(get(tx, checkinBucket, "h1|2025-01-03"), &hc); err != nil {
+					t.Fatal(err)
+				}
+				if hc.Amount != 1 {
+					t.Errorf("amount = %v, want 1", hc.Amount)
+				}
+			},
+		},
+		{
+			step: 5,
+			seed: func(t *testing.T, tx *bolt.Tx) {
+				put(t, tx, userBucket, "a@example.com", `{"email":"a@example.com","calendar_token":"cal"}`)
+				put(t, tx, userBucket, "b@example.com", `{"email":"b@example.com"}`)
+			},
+			check: func(t *testing.T, tx *bolt.Tx) {
+				if got := string(get(tx, usersByCalendarBucket, "cal")); got != "a@example.com" {
+					t.Errorf("calendar index: cal -> %q", got)
+				}
+				if n := tx.Bucket([]byte(usersByCalendarBucket)).Stats().KeyN; n != 1 {
+					t.Errorf("calendar index has %d keys, want 1", n)
+				}
+			},
+		},
+		{
+			step: 6,
+			check: func(t *testing.T, tx *bolt.Tx) {
+				if tx.Bucket([]byte(habitsByCreatedBucket)) != nil {
+					t.Error("habits_by_created is still there")
+				}
+				if tx.Bucket([]byte(habitsByUserBucket)) == nil {
+					t.Error("habits_by_user is gone")
+				}
+			},
+		},
+		{
+			step: 7,
+			seed: func(t *testing.T, tx *bolt.Tx) {
+				put(t, tx, userBucket, "a@example.com", `{"email":"a@example.com","calendar_token":"cal","heatmap_token":"map"}`)
+			},
+			check: func(t *testing.T, tx *bolt.Tx) {
+				if got := string(get(tx, usersByHeatmapBucket, "map")); got != "a@example.com" {
+					t.Errorf("heatmap index: map -> %q", got)
+				}
+				if get(tx, usersByHeatmapBucket, "cal") != nil {
+					t.Error("calendar token leaked into the heatmap index")
+				}
+			},
+		},
+	}
+	if len(tests) != len(migrations) {
+		t.Fatalf("%d steps tested, %d migrations defined", len(tests), len(migrations))
+	}
+
+	for _, tt := range tests {
+		t.Run(migrations[tt.step-1].name, func(t *testing.T) {
+			db := openRawDB(t)
+			applySteps(t, db, 1, tt.step-1)
+			if tt.seed != nil {
+				if err := db.Update(func(tx *bolt.Tx) error {
+					tt.seed(t, tx)
+					return nil
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// повторный запуск шага не должен ничего ломать
+			for run := 0; run < 2; run++ {
+				applySteps(t, db, tt.step, tt.step)
+				if err := db.View(func(tx *bolt.Tx) error {
+					tt.check(t, tx)
+					return nil
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// migrate записывает версию схемы и отказывается открывать базу новее кода
+func TestMigrateVersion(t *testing.T) {
+	db := openRawDB(t)
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := schemaVersion(db); err != nil || v != len(migrations) {
+		t.Fatalf("version = %d, %v, want %d", v, err, len(migrations))
+	}
+	// повторный запуск ничего не применяет
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(metaBucket)).Put([]byte(schemaVersionKey), []byte("99"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrate(db); err == nil || !strings.Contains(err.Error(), "newer than supported") {
+		t.Errorf("err = %v, want newer schema error", err)
+	}
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Snapshot пишет согласованную копию базы, не останавливая запись:
+// копия делается внутри read-транзакции BoltDB
+type Snapshot struct {
+	db *bolt.DB
+}
+
+func NewSnapshot(db *bolt.DB) *Snapshot {
+	return &Snapshot{db: db}
+}
+
+// WriteTo пишет копию базы в w (реализует io.WriterTo)
+func (s *Snapshot) WriteTo(w io.Writer) (n int64, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+// CheckSnapshot проверяет файл копии перед восстановлением: что это BoltDB,
+// что страницы не повреждены и что схема не новее поддерживаемой
+func CheckSnapshot(path string) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: 1 * time.Second})
+	if err != nil {
+		return fmt.Errorf("open snapshot: %w", err)
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		// канал нужно дочитать до конца, иначе проверка не завершится
+		var corrupted error
+		for err := range tx.Check() {
+			if corrupted == nil {
+				corrupted = err
+			}
+		}
+		if corrupted != nil {
+			return fmt.Errorf("snapshot is corrupted: %w", corrupted)
+		}
+		for _, name := range []string{userBucket, habitBucket, checkinBucket} {
+			if tx.Bucket([]byte(name)) == nil {
+				return fmt.Errorf("snapshot has no %s bucket", name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	v, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if v > len(migrations) {
+		return fmt.Errorf("snapshot schema version %d is newer than supported %d", v, len(migrations))
+	}
+	return nil
+}
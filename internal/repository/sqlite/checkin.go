@@ -0,0 +1,183 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"habit-tracker-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// HabitCheckinRepository — отметки в таблице habit_checkins,
+// одна строка на привычку и день (YYYY-MM-DD)
+type HabitCheckinRepository struct {
+	db *sql.DB
+}
+
+func NewHabitCheckinRepository(db *sql.DB) *HabitCheckinRepository {
+	return &HabitCheckinRepository{db: db}
+}
+
+const checkinColumns = `id, habit_id, date, amount, comment`
+
+// Create — добавляет отметку или суммирует количество с отметкой за тот же день
+func (r *HabitCheckinRepository) Create(hc *domain.HabitCheckin) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := createCheckin(tx, hc); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateMany — добавляет отметки одной транзакцией (по правилам Create)
+func (r *HabitCheckinRepository) CreateMany(hcs []domain.HabitCheckin) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i := range hcs {
+		if err := createCheckin(tx, &hcs[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func createCheckin(tx *sql.Tx, hc *domain.HabitCheckin) error {
+	day := hc.Date.Format("2006-01-02")
+	prev, err := scanCheckin(tx.QueryRow(
+		`SELECT `+checkinColumns+` FROM habit_checkins WHERE habit_id = ? AND day = ?`, hc.HabitID, day))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		hc.ID = uuid.New().String()
+		_, err = tx.Exec(`INSERT INTO habit_checkins (id, habit_id, day, date, amount, comment)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			hc.ID, hc.HabitID, day, formatLocalTime(hc.Date), hc.Amount, hc.Comment)
+	case err != nil:
+		return err
+	default:
+		hc.ID = prev.ID
+		hc.Date = prev.Date
+		hc.Amount += prev.AmountOrOne()
+		if hc.Comment == "" {
+			hc.Comment = prev.Comment
+		}
+		_, err = tx.Exec(`UPDATE habit_checkins SET amount = ?, comment = ? WHERE habit_id = ? AND day = ?`,
+			hc.Amount, hc.Comment, hc.HabitID, day)
+	}
+	return err
+}
+
+func (r *HabitCheckinRepository) FindByHabit(habitID string) ([]domain.HabitCheckin, error) {
+	rows, err := r.db.Query(
+		`SELECT `+checkinColumns+` FROM habit_checkins WHERE habit_id = ? ORDER BY day`, habitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []domain.HabitCheckin
+	for rows.Next() {
+		hc, err := scanCheckin(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *hc)
+	}
+	return res, rows.Err()
+}
+
+// FindByHabits — отметки нескольких привычек одним запросом
+func (r *HabitCheckinRepository) FindByHabits(habitIDs []string) (map[string][]domain.HabitCheckin, error) {
+	res := make(map[string][]domain.HabitCheckin, len(habitIDs))
+	if len(habitIDs) == 0 {
+		return res, nil
+	}
+	args := make([]any, len(habitIDs))
+	for i, id := range habitIDs {
+		args[i] = id
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(habitIDs)), ", ")
+	rows, err := r.db.Query(`SELECT `+checkinColumns+` FROM habit_checkins
+		WHERE habit_id IN (`+placeholders+`) ORDER BY habit_id, day`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		hc, err := scanCheckin(rows)
+		if err != nil {
+			return nil, err
+		}
+		res[hc.HabitID] = append(res[hc.HabitID], *hc)
+	}
+	return res, rows.Err()
+}
+
+func (r *HabitCheckinRepository) FindByHabitAndDate(habitID, day string) (*domain.HabitCheckin, error) {
+	hc, err := scanCheckin(r.db.QueryRow(
+		`SELECT `+checkinColumns+` FROM habit_checkins WHERE habit_id = ? AND day = ?`, habitID, day))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrCheckinNotFound
+	}
+	return hc, err
+}
+
+// Update — перезаписывает существующую отметку (без суммирования)
+func (r *HabitCheckinRepository) Update(hc *domain.HabitCheckin) error {
+	res, err := r.db.Exec(`UPDATE habit_checkins SET id = ?, date = ?, amount = ?, comment = ?
+		WHERE habit_id = ? AND day = ?`,
+		hc.ID, formatLocalTime(hc.Date), hc.Amount, hc.Comment, hc.HabitID, hc.Date.Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	return mustAffect(res, domain.ErrCheckinNotFound)
+}
+
+func (r *HabitCheckinRepository) Delete(habitID, day string) error {
+	res, err := r.db.Exec(`DELETE FROM habit_checkins WHERE habit_id = ? AND day = ?`, habitID, day)
+	if err != nil {
+		return err
+	}
+	return mustAffect(res, domain.ErrCheckinNotFound)
+}
+
+// ForEach — обходит все отметки по возрастанию (habit_id, day)
+func (r *HabitCheckinRepository) ForEach(fn func(hc *domain.HabitCheckin) error) error {
+	rows, err := r.db.Query(`SELECT ` + checkinColumns + ` FROM habit_checkins ORDER BY habit_id, day`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		hc, err := scanCheckin(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(hc); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanCheckin(s scanner) (*domain.HabitCheckin, error) {
+	var (
+		hc   domain.HabitCheckin
+		date string
+	)
+	if err := s.Scan(&hc.ID, &hc.HabitID, &date, &hc.Amount, &hc.Comment); err != nil {
+		return nil, err
+	}
+	var err error
+	if hc.Date, err = parseTime(date); err != nil {
+		return nil, err
+	}
+	return &hc, nil
+}
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+func TestCheckinCreateMergesDay(t *testing.T) {
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		first  float64 // количество первой записи за день (0 — запись без количества)
+		second float64
+		want   float64
+	}{
+		{"amounts add up", 2, 3, 5},
+		{"legacy record counts as one", 0, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewHabitCheckinRepository(openTestDB(t))
+			first := &domain.HabitCheckin{HabitID: "h", Date: day, Amount: 1, Comment: "morning"}
+			if err := r.Create(first); err != nil {
+				t.Fatal(err)
+			}
+			// первую запись сохраняем как есть, в обход суммирования
+			first.Amount = tt.first
+			if err := r.Update(first); err != nil {
+				t.Fatal(err)
+			}
+			if err := r.Create(&domain.HabitCheckin{HabitID: "h", Date: day.Add(3 * time.Hour), Amount: tt.second}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.FindByHabitAndDate("h", "2026-10-15")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Amount != tt.want || got.ID != first.ID || got.Comment != "morning" {
+				t.Errorf("got %+v, want amount %v, id %s, first comment", got, tt.want, first.ID)
+			}
+		})
+	}
+}
+
+func TestCheckinNotFound(t *testing.T) {
+	r := NewHabitCheckinRepository(openTestDB(t))
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"find", func() error { _, err := r.FindByHabitAndDate("h", "2026-10-15"); return err }()},
+		{"update", r.Update(&domain.HabitCheckin{HabitID: "h", Date: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)})},
+		{"delete", r.Delete("h", "2026-10-15")},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, domain.ErrCheckinNotFound) {
+			t.Errorf("%s: err = %v, want ErrCheckinNotFound", tt.name, tt.err)
+		}
+	}
+}
+
+// Отметки возвращаются по дням, сгруппированные по привычкам
+func TestCheckinFindByHabits(t *testing.T) {
+	r := NewHabitCheckinRepository(openTestDB(t))
+	base := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	err := r.CreateMany([]domain.HabitCheckin{
+		{HabitID: "a", Date: base.AddDate(0, 0, 1), Amount: 1},
+		{HabitID: "b", Date: base, Amount: 1},
+		{HabitID: "a", Date: base, Amount: 1},
+		{HabitID: "c", Date: base, Amount: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.FindByHabits([]string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || len(got["a"]) != 2 || len(got["b"]) != 1 {
+		t.Fatalf("got %v", got)
+	}
+	if !got["a"][0].Date.Before(got["a"][1].Date) {
+		t.Errorf("checkins of a are not sorted: %v", got["a"])
+	}
+}
@@ -0,0 +1,192 @@
+// Package sqlite — хранилища поверх SQLite (драйвер modernc.org/sqlite, без cgo).
+// Схема создаётся и обновляется миграциями при открытии базы.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// timeLayout — формат хранения времени. Моменты (created_at, сроки токенов)
+// пишутся в UTC: при фиксированной ширине их можно сравнивать и сортировать
+// как строки. Дата отметки сохраняет смещение пояса, чтобы день отметки
+// не «съезжал» при чтении
+const timeLayout = "2006-01-02T15:04:05.000000000Z07:00"
+
+// toUTC — SQL-выражение, переводящее значение колонки из timeLayout
+// со смещением в UTC; дробная часть переносится как есть
+func toUTC(table, column string) string {
+	return fmt.Sprintf(`UPDATE %[1]s SET %[2]s = strftime('%%Y-%%m-%%dT%%H:%%M:%%S', substr(%[2]s, 1, 19) || substr(%[2]s, 30))
+		|| substr(%[2]s, 20, 10) || 'Z' WHERE %[2]s NOT LIKE '%%Z';`, table, column)
+}
+
+// migrations — схема по шагам; применённые шаги записываются в schema_migrations.
+// Новые шаги только дописываются в конец.
+var migrations = []string{
+	// 1: начальная схема
+	`CREATE TABLE users (
+		email      TEXT PRIMARY KEY,
+		password   TEXT NOT NULL,
+		timezone   TEXT NOT NULL DEFAULT '',
+		verified   INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT NOT NULL
+	);
+	CREATE TABLE habits (
+		id         TEXT PRIMARY KEY,
+		user_email TEXT NOT NULL,
+		name       TEXT NOT NULL,
+		goal       TEXT NOT NULL DEFAULT '',
+		schedule   TEXT NOT NULL DEFAULT '{}',
+		target     REAL NOT NULL DEFAULT 0,
+		unit       TEXT NOT NULL DEFAULT '',
+		created_at TEXT NOT NULL
+	);
+	CREATE INDEX habits_user_email ON habits (user_email, created_at);
+	CREATE TABLE habit_checkins (
+		id       TEXT NOT NULL,
+		habit_id TEXT NOT NULL,
+		day      TEXT NOT NULL,
+		date     TEXT NOT NULL,
+		amount   REAL NOT NULL,
+		comment  TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (habit_id, day)
+	);
+	CREATE INDEX habit_checkins_day ON habit_checkins (day);
+	CREATE TABLE refresh_tokens (
+		hash       TEXT PRIMARY KEY,
+		user_email TEXT NOT NULL,
+		expires_at TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);
+	CREATE INDEX refresh_tokens_user_email ON refresh_tokens (user_email);
+	CREATE TABLE revoked_tokens (
+		token_id   TEXT PRIMARY KEY,
+		expires_at TEXT NOT NULL
+	);
+	CREATE TABLE revoke_all (
+		email TEXT PRIMARY KEY,
+		since TEXT NOT NULL
+	);
+	CREATE TABLE user_tokens (
+		hash       TEXT PRIMARY KEY,
+		user_email TEXT NOT NULL,
+		purpose    TEXT NOT NULL,
+		expires_at TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	);`,
+	// 2: архив и корзина привычек
+	`ALTER TABLE habits ADD COLUMN archived_at TEXT;
+	ALTER TABLE habits ADD COLUMN deleted_at TEXT;`,
+	// 3: дни отдыха привычек (JSON-массив дат)
+	`ALTER TABLE habits ADD COLUMN freeze_days TEXT NOT NULL DEFAULT '[]';`,
+	// 4: хэш токена ленты календаря (NULL — лента выключена)
+	`ALTER TABLE users ADD COLUMN calendar_token TEXT;
+	CREATE UNIQUE INDEX users_calendar_token ON users (calendar_token);`,
+	// 5: моменты, записанные с местным смещением, переводятся в UTC
+	toUTC("users", "created_at") +
+		toUTC("habits", "created_at") +
+		toUTC("habits", "archived_at") +
+		toUTC("habits", "deleted_at") +
+		toUTC("refresh_tokens", "expires_at") +
+		toUTC("refresh_tokens", "created_at") +
+		toUTC("user_tokens", "expires_at") +
+		toUTC("user_tokens", "created_at"),
+	// 6: хэш токена публичной тепловой карты (NULL — карта закрыта)
+	`ALTER TABLE users ADD COLUMN heatmap_token TEXT;
+	CREATE UNIQUE INDEX users_heatmap_token ON users (heatmap_token);`,
+}
+
+// Open открывает (или создаёт) файл SQLite и применяет недостающие миграции
+func Open(path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func migrate(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+	var current int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than supported %d", current, len(migrations))
+	}
+	for v := current + 1; v <= len(migrations); v++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[v-1]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", v, err)
+		}
+		_, err = tx.Exec(`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+			v, time.Now().UTC().Format(timeLayout))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatTime — момент времени, всегда в UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// formatLocalTime — время с исходным смещением пояса (дата отметки)
+func formatLocalTime(t time.Time) string {
+	return t.Format(timeLayout)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(timeLayout, s)
+}
+
+// nullTime — необязательное время: nil хранится как NULL
+func nullTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// nullString — необязательная строка: пустая хранится как NULL
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// HabitRepository — привычки в таблице habits
+type HabitRepository struct {
+	db *sql.DB
+}
+
+func NewHabitRepository(db *sql.DB) *HabitRepository {
+	return &HabitRepository{db: db}
+}
+
+const habitColumns = `id, user_email, name, goal, schedule, target, unit, created_at, archived_at, deleted_at, freeze_days`
+
+func (r *HabitRepository) Create(h *domain.Habit) error {
+	if h.ID == "" {
+		h.ID = uuid.New().String()
+	}
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = time.Now()
+	}
+	schedule, freezeDays, err := marshalHabitJSON(h)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`INSERT INTO habits (`+habitColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		h.ID, h.UserEmail, h.Name, h.Goal, schedule, h.Target, h.Unit, formatTime(h.CreatedAt),
+		nullTime(h.ArchivedAt), nullTime(h.DeletedAt), freezeDays)
+	return err
+}
+
+func (r *HabitRepository) FindAllByUser(email string) ([]*domain.Habit, error) {
+	rows, err := r.db.Query(`SELECT `+habitColumns+` FROM habits WHERE user_email = ? ORDER BY created_at, id`, email)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var habits []*domain.Habit
+	for rows.Next() {
+		h, err := scanHabit(rows)
+		if err != nil {
+			return nil, err
+		}
+		habits = append(habits, h)
+	}
+	return habits, rows.Err()
+}
+
+func (r *HabitRepository) FindByID(id string) (*domain.Habit, error) {
+	h, err := scanHabit(r.db.QueryRow(`SELECT `+habitColumns+` FROM habits WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrHabitNotFound
+	}
+	return h, err
+}
+
+func (r *HabitRepository) Update(h *domain.Habit) error {
+	schedule, freezeDays, err := marshalHabitJSON(h)
+	if err != nil {
+		return err
+	}
+	res, err := r.db.Exec(`UPDATE habits SET user_email = ?, name = ?, goal = ?, schedule = ?,
+		target = ?, unit = ?, created_at = ?, archived_at = ?, deleted_at = ?, freeze_days = ? WHERE id = ?`,
+		h.UserEmail, h.Name, h.Goal, schedule, h.Target, h.Unit, formatTime(h.CreatedAt),
+		nullTime(h.ArchivedAt), nullTime(h.DeletedAt), freezeDays, h.ID)
+	if err != nil {
+		return err
+	}
+	return mustAffect(res, domain.ErrHabitNotFound)
+}
+
+// Delete — удаляет привычку вместе с её отметками
+func (r *HabitRepository) Delete(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(`DELETE FROM habits WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if err := mustAffect(res, domain.ErrHabitNotFound); err != nil {
+		return err
+	}
+	// отметки удаляются вместе с привычкой
+	if _, err := tx.Exec(`DELETE FROM habit_checkins WHERE habit_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ForEach — обходит все привычки по возрастанию ID
+func (r *HabitRepository) ForEach(fn func(h *domain.Habit) error) error {
+	rows, err := r.db.Query(`SELECT ` + habitColumns + ` FROM habits ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		h, err := scanHabit(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(h); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scanner — общий интерфейс *sql.Row и *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// marshalHabitJSON — поля привычки, которые хранятся в колонках как JSON
+func marshalHabitJSON(h *domain.Habit) (schedule, freezeDays string, err error) {
+	s, err := json.Marshal(h.Schedule)
+	if err != nil {
+		return "", "", err
+	}
+	days := h.FreezeDays
+	if days == nil {
+		days = []string{}
+	}
+	f, err := json.Marshal(days)
+	if err != nil {
+		return "", "", err
+	}
+	return string(s), string(f), nil
+}
+
+func scanHabit(s scanner) (*domain.Habit, error) {
+	var (
+		h                     domain.Habit
+		schedule, createdAt   string
+		archivedAt, deletedAt sql.NullString
+		freezeDays            string
+	)
+	if err := s.Scan(&h.ID, &h.UserEmail, &h.Name, &h.Goal, &schedule, &h.Target, &h.Unit, &createdAt,
+		&archivedAt, &deletedAt, &freezeDays); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(schedule), &h.Schedule); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(freezeDays), &h.FreezeDays); err != nil {
+		return nil, err
+	}
+	var err error
+	if h.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	if h.ArchivedAt, err = parseNullTime(archivedAt); err != nil {
+		return nil, err
+	}
+	if h.DeletedAt, err = parseNullTime(deletedAt); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// mustAffect возвращает ошибку notFound, если запрос не затронул ни одной строки
+func mustAffect(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "habits.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// Привычки пользователя идут по моменту создания, а не по смещению,
+// с которым он был записан
+func TestHabitFindAllByUserOrder(t *testing.T) {
+	r := NewHabitRepository(openTestDB(t))
+	moscow := time.FixedZone("MSK", 3*60*60)
+	newYork := time.FixedZone("EST", -5*60*60)
+	base := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	habits := []struct {
+		name    string
+		created time.Time
+	}{
+		{"third", base.Add(2 * time.Hour).In(moscow)},
+		{"first", base.In(newYork)},
+		{"second", base.Add(time.Hour).In(time.UTC)},
+	}
+	for _, h := range habits {
+		err := r.Create(&domain.Habit{UserEmail: "a@example.com", Name: h.name, CreatedAt: h.created})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := r.FindAllByUser("a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"first", "second", "third"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d habits, want %d", len(got), len(want))
+	}
+	for i, h := range got {
+		if h.Name != want[i] {
+			t.Errorf("habit %d = %s, want %s", i, h.Name, want[i])
+		}
+	}
+}
+
+// Миграция 5 переводит уже записанные со смещением моменты в UTC
+func TestMigrateTimestampsToUTC(t *testing.T) {
+	db := openTestDB(t)
+
+	tests := []struct {
+		stored, want string
+	}{
+		{"2026-10-15T01:30:00.123456789+03:00", "2026-10-14T22:30:00.123456789Z"},
+		{"2026-10-15T23:30:00.999999999-05:00", "2026-10-16T04:30:00.999999999Z"},
+		{"2026-10-15T12:00:00.000000000Z", "2026-10-15T12:00:00.000000000Z"},
+	}
+	for i, tt := range tests {
+		_, err := db.Exec(`INSERT INTO habits (id, user_email, name, created_at) VALUES (?, ?, ?, ?)`,
+			i, "a@example.com", "habit", tt.stored)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(migrations[4]); err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range tests {
+		var got string
+		var archived sql.NullString
+		if err := db.QueryRow(`SELECT created_at, archived_at FROM habits WHERE id = ?`, i).Scan(&got, &archived); err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s -> %s, want %s", tt.stored, got, tt.want)
+		}
+		if archived.Valid {
+			t.Errorf("archived_at = %q, want NULL", archived.String)
+		}
+	}
+}
+
+// Дата отметки хранит смещение пояса, чтобы день не «съезжал»
+func TestCheckinKeepsOffset(t *testing.T) {
+	r := NewHabitCheckinRepository(openTestDB(t))
+	moscow := time.FixedZone("MSK", 3*60*60)
+	date := time.Date(2026, 10, 15, 1, 0, 0, 0, moscow)
+	if err := r.Create(&domain.HabitCheckin{HabitID: "h", Date: date, Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.FindByHabitAndDate("h", "2026-10-15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Date.Format("2006-01-02") != "2026-10-15" || !got.Date.Equal(date) {
+		t.Errorf("Date = %v, want %v", got.Date, date)
+	}
+}
+
+// Удаление привычки уносит её отметки; отсутствующая привычка — ErrHabitNotFound
+func TestHabitDelete(t *testing.T) {
+	db := openTestDB(t)
+	habits, checkins := NewHabitRepository(db), NewHabitCheckinRepository(db)
+	h := &domain.Habit{UserEmail: "a@example.com", Name: "Read"}
+	if err := habits.Create(h); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkins.Create(&domain.HabitCheckin{HabitID: h.ID, Date: time.Now(), Amount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := habits.Delete(h.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := checkins.FindByHabit(h.ID); err != nil || len(got) != 0 {
+		t.Errorf("checkins after delete: %v, %v", got, err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"find", func() error { _, err := habits.FindByID(h.ID); return err }()},
+		{"update", habits.Update(h)},
+		{"delete", habits.Delete(h.ID)},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, domain.ErrHabitNotFound) {
+			t.Errorf("%s: err = %v, want ErrHabitNotFound", tt.name, tt.err)
+		}
+	}
+}
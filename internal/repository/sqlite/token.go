@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// TokenRepository — refresh-токены, отзывы и одноразовые токены из писем
+type TokenRepository struct {
+	db *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+func (r *TokenRepository) SaveRefreshToken(rt *domain.RefreshToken) error {
+	_, err := r.db.Exec(`INSERT OR REPLACE INTO refresh_tokens (hash, user_email, expires_at, created_at)
+		VALUES (?, ?, ?, ?)`,
+		rt.Hash, rt.UserEmail, formatTime(rt.ExpiresAt), formatTime(rt.CreatedAt))
+	return err
+}
+
+// ConsumeRefreshToken — достаёт и сразу удаляет refresh-токен (одноразовый обмен)
+func (r *TokenRepository) ConsumeRefreshToken(hash string) (*domain.RefreshToken, error) {
+	var (
+		rt                   domain.RefreshToken
+		expiresAt, createdAt string
+	)
+	err := r.db.QueryRow(`DELETE FROM refresh_tokens WHERE hash = ?
+		RETURNING hash, user_email, expires_at, created_at`, hash).
+		Scan(&rt.Hash, &rt.UserEmail, &expiresAt, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("refresh token not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rt.ExpiresAt, err = parseTime(expiresAt); err != nil {
+		return nil, err
+	}
+	if rt.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &rt, nil
+}
+
+func (r *TokenRepository) DeleteRefreshToken(email, hash string) error {
+	_, err := r.db.Exec(`DELETE FROM refresh_tokens WHERE hash = ? AND user_email = ?`, hash, email)
+	return err
+}
+
+func (r *TokenRepository) RevokeAll(email string, since time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM refresh_tokens WHERE user_email = ?`, email); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO revoke_all (email, since) VALUES (?, ?)
+		ON CONFLICT (email) DO UPDATE SET since = excluded.since`,
+		email, formatTime(since.UTC()))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TokenRepository) RevokedBefore(email string) (time.Time, error) {
+	var since string
+	err := r.db.QueryRow(`SELECT since FROM revoke_all WHERE email = ?`, email).Scan(&since)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseTime(since)
+}
+
+// RevokeAccessToken — помечает access-токен отозванным и чистит истёкшие записи
+func (r *TokenRepository) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// время хранится в UTC, поэтому строки можно сравнивать напрямую
+	if _, err := tx.Exec(`DELETE FROM revoked_tokens WHERE expires_at < ?`,
+		formatTime(time.Now().UTC())); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT OR REPLACE INTO revoked_tokens (token_id, expires_at) VALUES (?, ?)`,
+		tokenID, formatTime(expiresAt.UTC()))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *TokenRepository) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	var n int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM revoked_tokens WHERE token_id = ?`, tokenID).Scan(&n)
+	return n > 0, err
+}
+
+func (r *TokenRepository) SaveUserToken(ut *domain.UserToken) error {
+	_, err := r.db.Exec(`INSERT OR REPLACE INTO user_tokens (hash, user_email, purpose, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		ut.Hash, ut.UserEmail, ut.Purpose, formatTime(ut.ExpiresAt), formatTime(ut.CreatedAt))
+	return err
+}
+
+// ConsumeUserToken — достаёт и удаляет одноразовый токен с нужным назначением
+func (r *TokenRepository) ConsumeUserToken(hash, purpose string) (*domain.UserToken, error) {
+	var (
+		ut                   domain.UserToken
+		expiresAt, createdAt string
+	)
+	err := r.db.QueryRow(`DELETE FROM user_tokens WHERE hash = ? AND purpose = ?
+		RETURNING hash, user_email, purpose, expires_at, created_at`, hash, purpose).
+		Scan(&ut.Hash, &ut.UserEmail, &ut.Purpose, &expiresAt, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("token not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ut.ExpiresAt, err = parseTime(expiresAt); err != nil {
+		return nil, err
+	}
+	if ut.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &ut, nil
+}
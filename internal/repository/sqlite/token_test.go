@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+func TestRefreshTokenConsumedOnce(t *testing.T) {
+	r := NewTokenRepository(openTestDB(t))
+	now := time.Now()
+	rt := &domain.RefreshToken{Hash: "h", UserEmail: "a@example.com", ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	if err := r.SaveRefreshToken(rt); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.ConsumeRefreshToken("h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.ExpiresAt.Equal(rt.ExpiresAt) || got.ExpiresAt.Location() != time.UTC {
+		t.Errorf("ExpiresAt = %v, want %v in UTC", got.ExpiresAt, rt.ExpiresAt)
+	}
+	if _, err := r.ConsumeRefreshToken("h"); err == nil {
+		t.Error("second consume: expected error")
+	}
+
+	// «выйти везде» удаляет оставшиеся refresh-токены пользователя
+	if err := r.SaveRefreshToken(rt); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RevokeAll("a@example.com", now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ConsumeRefreshToken("h"); err == nil {
+		t.Error("consume after revoke all: expected error")
+	}
+	if before, err := r.RevokedBefore("a@example.com"); err != nil || !before.Equal(now) {
+		t.Errorf("RevokedBefore = %v, %v, want %v", before, err, now)
+	}
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// UserRepository — пользователи в таблице users
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+const userColumns = `email, password, timezone, verified, created_at, calendar_token, heatmap_token`
+
+func (r *UserRepository) Create(user *domain.User) error {
+	res, err := r.db.Exec(`INSERT INTO users (`+userColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (email) DO NOTHING`,
+		user.Email, user.Password, user.Timezone, user.Verified, formatTime(user.CreatedAt),
+		nullString(user.CalendarToken), nullString(user.HeatmapToken))
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errors.New("user already exists")
+	}
+	return nil
+}
+
+func (r *UserRepository) FindByEmail(email string) (*domain.User, error) {
+	u, err := scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE email = ?`, email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("user not found")
+	}
+	return u, err
+}
+
+func (r *UserRepository) FindByCalendarToken(hash string) (*domain.User, error) {
+	u, err := scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE calendar_token = ?`, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("user not found")
+	}
+	return u, err
+}
+
+func (r *UserRepository) FindByHeatmapToken(hash string) (*domain.User, error) {
+	u, err := scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE heatmap_token = ?`, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("user not found")
+	}
+	return u, err
+}
+
+func (r *UserRepository) Update(user *domain.User) error {
+	res, err := r.db.Exec(`UPDATE users SET password = ?, timezone = ?, verified = ?, created_at = ?,
+		calendar_token = ?, heatmap_token = ? WHERE email = ?`,
+		user.Password, user.Timezone, user.Verified, formatTime(user.CreatedAt),
+		nullString(user.CalendarToken), nullString(user.HeatmapToken), user.Email)
+	if err != nil {
+		return err
+	}
+	return mustAffect(res, errors.New("user not found"))
+}
+
+// ForEach — обходит всех пользователей по возрастанию email
+func (r *UserRepository) ForEach(fn func(u *domain.User) error) error {
+	rows, err := r.db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY email`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanUser(s scanner) (*domain.User, error) {
+	var (
+		u             domain.User
+		createdAt     string
+		calendarToken sql.NullString
+		heatmapToken  sql.NullString
+	)
+	if err := s.Scan(&u.Email, &u.Password, &u.Timezone, &u.Verified, &createdAt, &calendarToken, &heatmapToken); err != nil {
+		return nil, err
+	}
+	u.CalendarToken = calendarToken.String
+	u.HeatmapToken = heatmapToken.String
+	var err error
+	if u.CreatedAt, err = parseTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
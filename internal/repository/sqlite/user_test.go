@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"testing"
+
+	"habit-tracker-api/internal/domain"
+)
+
+func TestUserTokens(t *testing.T) {
+	r := NewUserRepository(openTestDB(t))
+	// пустые токены хранятся как NULL и не мешают уникальному индексу
+	for _, u := range []*domain.User{
+		{Email: "a@example.com", CalendarToken: "cal-a", HeatmapToken: "map-a"},
+		{Email: "b@example.com"},
+		{Email: "c@example.com"},
+	} {
+		if err := r.Create(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Create(&domain.User{Email: "a@example.com"}); err == nil {
+		t.Error("duplicate email: expected error")
+	}
+
+	tests := []struct {
+		name  string
+		find  func(string) (*domain.User, error)
+		hash  string
+		email string // пусто — пользователь не найден
+	}{
+		{"calendar token", r.FindByCalendarToken, "cal-a", "a@example.com"},
+		{"heatmap token", r.FindByHeatmapToken, "map-a", "a@example.com"},
+		{"tokens are separate", r.FindByHeatmapToken, "cal-a", ""},
+		{"empty calendar token", r.FindByCalendarToken, "", ""},
+		{"empty heatmap token", r.FindByHeatmapToken, "", ""},
+	}
+	for _, tt := range tests {
+		u, err := tt.find(tt.hash)
+		switch {
+		case tt.email == "" && err == nil:
+			t.Errorf("%s: found %s, want not found", tt.name, u.Email)
+		case tt.email != "" && (err != nil || u.Email != tt.email):
+			t.Errorf("%s: got %v, %v, want %s", tt.name, u, err, tt.email)
+		}
+	}
+
+	// смена токена: старый перестаёт находиться
+	u, err := r.FindByEmail("a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.HeatmapToken = "map-a2"
+	if err := r.Update(u); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.FindByHeatmapToken("map-a"); err == nil {
+		t.Error("old heatmap token still works")
+	}
+	if got, err := r.FindByHeatmapToken("map-a2"); err != nil || got.CalendarToken != "cal-a" {
+		t.Errorf("new heatmap token: %v, %v", got, err)
+	}
+	if err := r.Update(&domain.User{Email: "nobody@example.com"}); err == nil {
+		t.Error("update of a missing user: expected error")
+	}
+}
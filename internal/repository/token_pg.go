@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	refreshTokenBucket = "refresh_tokens" // хэш refresh-токена -> domain.RefreshToken
+	revokedTokenBucket = "revoked_tokens" // ID access-токена -> срок его действия
+	revokeAllBucket    = "revoke_all"     // email -> токены, выданные раньше, недействительны
+	userTokenBucket    = "user_tokens"    // хэш одноразового токена -> domain.UserToken
+)
+
+// TokenRepository хранит refresh-токены и отозванные access-токены
+type TokenRepository struct {
+	db *bolt.DB
+}
+
+func NewTokenRepository(db *bolt.DB) *TokenRepository {
+	_ = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range []string{refreshTokenBucket, revokedTokenBucket, revokeAllBucket, userTokenBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return &TokenRepository{db: db}
+}
+
+// SaveRefreshToken — сохраняет выданный refresh-токен
+func (r *TokenRepository) SaveRefreshToken(rt *domain.RefreshToken) error {
+	data, err := json.Marshal(rt)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(refreshTokenBucket)).Put([]byte(rt.Hash), data)
+	})
+}
+
+// ConsumeRefreshToken — достаёт и сразу удаляет refresh-токен,
+// чтобы каждый токен можно было обменять только один раз
+func (r *TokenRepository) ConsumeRefreshToken(hash string) (*domain.RefreshToken, error) {
+	var rt domain.RefreshToken
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(refreshTokenBucket))
+		v := b.Get([]byte(hash))
+		if v == nil {
+			return errors.New("refresh token not found")
+		}
+		if err := json.Unmarshal(v, &rt); err != nil {
+			return err
+		}
+		return b.Delete([]byte(hash))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rt, nil
+}
+
+// DeleteRefreshToken — удаляет refresh-токен пользователя, если он есть
+func (r *TokenRepository) DeleteRefreshToken(email, hash string) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(refreshTokenBucket))
+		v := b.Get([]byte(hash))
+		if v == nil {
+			return nil
+		}
+		var rt domain.RefreshToken
+		if err := json.Unmarshal(v, &rt); err != nil {
+			return err
+		}
+		if rt.UserEmail != email {
+			return nil
+		}
+		return b.Delete([]byte(hash))
+	})
+}
+
+// RevokeAll — удаляет все refresh-токены пользователя и делает
+// недействительными его access-токены, выданные до since
+func (r *TokenRepository) RevokeAll(email string, since time.Time) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(refreshTokenBucket))
+		var stale [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			var rt domain.RefreshToken
+			if err := json.Unmarshal(v, &rt); err != nil {
+				return err
+			}
+			if rt.UserEmail == email {
+				stale = append(stale, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket([]byte(revokeAllBucket)).Put([]byte(email), []byte(since.UTC().Format(time.RFC3339)))
+	})
+}
+
+// RevokedBefore — момент последнего «выхода на всех устройствах» (нулевой, если не было)
+func (r *TokenRepository) RevokedBefore(email string) (time.Time, error) {
+	var t time.Time
+	err := r.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket([]byte(revokeAllBucket)).Get([]byte(email))
+		if v == nil {
+			return nil
+		}
+		var err error
+		t, err = time.Parse(time.RFC3339, string(v))
+		return err
+	})
+	return t, err
+}
+
+// RevokeAccessToken — помечает access-токен отозванным до истечения его срока.
+// Заодно вычищает записи о токенах, которые уже истекли сами.
+func (r *TokenRepository) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(revokedTokenBucket))
+		now := time.Now()
+		var expired [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			if t, err := time.Parse(time.RFC3339, string(v)); err == nil && t.Before(now) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return b.Put([]byte(tokenID), []byte(expiresAt.UTC().Format(time.RFC3339)))
+	})
+}
+
+// IsAccessTokenRevoked — отозван ли access-токен с данным ID
+func (r *TokenRepository) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	var revoked bool
+	err := r.db.View(func(tx *bolt.Tx) error {
+		revoked = tx.Bucket([]byte(revokedTokenBucket)).Get([]byte(tokenID)) != nil
+		return nil
+	})
+	return revoked, err
+}
+
+// SaveUserToken — сохраняет одноразовый токен из письма
+func (r *TokenRepository) SaveUserToken(ut *domain.UserToken) error {
+	data, err := json.Marshal(ut)
+	if err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(userTokenBucket)).Put([]byte(ut.Hash), data)
+	})
+}
+
+// ConsumeUserToken — достаёт и удаляет одноразовый токен с нужным назначением
+func (r *TokenRepository) ConsumeUserToken(hash, purpose string) (*domain.UserToken, error) {
+	var ut domain.UserToken
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(userTokenBucket))
+		v := b.Get([]byte(hash))
+		if v == nil {
+			return errors.New("token not found")
+		}
+		if err := json.Unmarshal(v, &ut); err != nil {
+			return err
+		}
+		if ut.Purpose != purpose {
+			return errors.New("token not found")
+		}
+		return b.Delete([]byte(hash))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ut, nil
+}
@@ -9,28 +9,35 @@ import (
 	bolt "go.etcd.io/bbolt"
 )
 
-type UserRepository struct{}
+// Индексы пользователей по хэшам секретных токенов -> email
+const (
+	usersByCalendarBucket = "users_by_calendar_token"
+	usersByHeatmapBucket  = "users_by_heatmap_token"
+)
+
+type UserRepository struct {
+	db *bolt.DB
+}
 
-// NewUserRepository просто гарантирует, что DB инициализирована
-func NewUserRepository() *UserRepository {
-	return &UserRepository{}
+// NewUserRepository — бакет пользователей создаётся в InitDB
+func NewUserRepository(db *bolt.DB) *UserRepository {
+	return &UserRepository{db: db}
 }
 
 func (r *UserRepository) Create(user *domain.User) error {
-	return DB.Update(func(tx *bolt.Tx) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(userBucket))
 		// проверим, нет ли такого email
 		if b.Get([]byte(user.Email)) != nil {
 			return errors.New("user already exists")
 		}
-		data, _ := json.Marshal(user)
-		return b.Put([]byte(user.Email), data)
+		return putUser(tx, user, nil)
 	})
 }
 
 func (r *UserRepository) FindByEmail(email string) (*domain.User, error) {
 	var user domain.User
-	err := DB.View(func(tx *bolt.Tx) error {
+	err := r.db.View(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(userBucket))
 		v := b.Get([]byte(email))
 		if v == nil {
@@ -43,3 +50,94 @@ func (r *UserRepository) FindByEmail(email string) (*domain.User, error) {
 	}
 	return &user, nil
 }
+
+// FindByCalendarToken — ищет пользователя через индекс токенов календаря
+func (r *UserRepository) FindByCalendarToken(hash string) (*domain.User, error) {
+	return r.findByToken(usersByCalendarBucket, hash)
+}
+
+// FindByHeatmapToken — ищет пользователя через индекс токенов тепловой карты
+func (r *UserRepository) FindByHeatmapToken(hash string) (*domain.User, error) {
+	return r.findByToken(usersByHeatmapBucket, hash)
+}
+
+func (r *UserRepository) findByToken(index, hash string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.View(func(tx *bolt.Tx) error {
+		email := tx.Bucket([]byte(index)).Get([]byte(hash))
+		if email == nil {
+			return errors.New("user not found")
+		}
+		v := tx.Bucket([]byte(userBucket)).Get(email)
+		if v == nil {
+			return errors.New("user not found")
+		}
+		return json.Unmarshal(v, &user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update — перезаписывает существующего пользователя
+func (r *UserRepository) Update(user *domain.User) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		v := tx.Bucket([]byte(userBucket)).Get([]byte(user.Email))
+		if v == nil {
+			return errors.New("user not found")
+		}
+		var old domain.User
+		if err := json.Unmarshal(v, &old); err != nil {
+			return err
+		}
+		return putUser(tx, user, &old)
+	})
+}
+
+// putUser сохраняет пользователя и обновляет индексы токенов календаря
+// и тепловой карты; old — прежняя версия записи (nil для нового пользователя)
+func putUser(tx *bolt.Tx, user, old *domain.User) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket([]byte(userBucket)).Put([]byte(user.Email), data); err != nil {
+		return err
+	}
+	var oldCalendar, oldHeatmap string
+	if old != nil {
+		oldCalendar, oldHeatmap = old.CalendarToken, old.HeatmapToken
+	}
+	if err := putTokenIndex(tx, usersByCalendarBucket, user.Email, oldCalendar, user.CalendarToken); err != nil {
+		return err
+	}
+	return putTokenIndex(tx, usersByHeatmapBucket, user.Email, oldHeatmap, user.HeatmapToken)
+}
+
+// putTokenIndex заменяет в индексе old на token (пустой токен — записи нет)
+func putTokenIndex(tx *bolt.Tx, index, email, old, token string) error {
+	idx := tx.Bucket([]byte(index))
+	if old != "" && old != token {
+		if err := idx.Delete([]byte(old)); err != nil {
+			return err
+		}
+	}
+	if token == "" {
+		return nil
+	}
+	return idx.Put([]byte(token), []byte(email))
+}
+
+// ForEach — обходит всех пользователей в порядке ключей (email)
+func (r *UserRepository) ForEach(fn func(u *domain.User) error) error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(userBucket)).ForEach(func(_, v []byte) error {
+			var u domain.User
+			if err := json.Unmarshal(v, &u); err != nil {
+				return err
+			}
+			return fn(&u)
+		})
+	})
+}
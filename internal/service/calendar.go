@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"habit-tracker-api/internal/auth"
+	"habit-tracker-api/internal/domain"
+)
+
+// calendarHistoryDays — за сколько последних дней лента показывает выполненные дни
+const calendarHistoryDays = 365
+
+// ErrCalendarNotFound — токен ленты неизвестен или лента выключена
+var ErrCalendarNotFound = errors.New("calendar not found")
+
+// EnableCalendar выпускает новый секретный токен ленты календаря и возвращает
+// её адрес. Прежний адрес перестаёт работать; в базе хранится только хэш токена.
+func (s *UserService) EnableCalendar(email string) (string, error) {
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return "", err
+	}
+	token, tokenHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	user.CalendarToken = tokenHash
+	if err := s.repo.Update(user); err != nil {
+		return "", err
+	}
+	return s.appURL + "/calendar/" + token + ".ics", nil
+}
+
+// DisableCalendar выключает ленту календаря
+func (s *UserService) DisableCalendar(email string) error {
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	user.CalendarToken = ""
+	return s.repo.Update(user)
+}
+
+// Calendar — данные ленты календаря пользователя
+type Calendar struct {
+	UserEmail string
+	Habits    []CalendarHabit
+}
+
+// CalendarHabit — привычка как повторяющееся событие. Даты — YYYY-MM-DD в поясе
+// пользователя: Start — день создания, Until — день архивации (пусто — без конца).
+type CalendarHabit struct {
+	Habit *domain.Habit
+	Start string
+	Until string
+	Done  []CalendarDay // выполненные дни по возрастанию
+}
+
+// CalendarDay — выполненный день привычки и сделанное количество
+type CalendarDay struct {
+	Date   string
+	Amount float64
+}
+
+// CalendarService собирает ленту календаря из привычек и их отчётов
+type CalendarService struct {
+	users    UserStore
+	habits   *HabitService
+	checkins *HabitCheckinService
+}
+
+func NewCalendarService(users UserStore, habits *HabitService, checkins *HabitCheckinService) *CalendarService {
+	return &CalendarService{users, habits, checkins}
+}
+
+// Calendar возвращает ленту по секретному токену из адреса.
+// В ленту идут активные и архивные привычки; привычки из корзины — нет.
+func (s *CalendarService) Calendar(token string) (*Calendar, error) {
+	if token == "" {
+		return nil, ErrCalendarNotFound
+	}
+	user, err := s.users.FindByCalendarToken(auth.HashToken(token))
+	if err != nil {
+		return nil, ErrCalendarNotFound
+	}
+	habits, err := s.habits.GetAll(user.Email)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(habits, func(i, j int) bool { return habits[i].CreatedAt.Before(habits[j].CreatedAt) })
+
+	loc := userLocation(s.users, user.Email)
+	since := startOfDay(time.Now(), loc).AddDate(0, 0, -calendarHistoryDays).Format("2006-01-02")
+	cal := &Calendar{UserEmail: user.Email, Habits: make([]CalendarHabit, 0, len(habits))}
+	for _, h := range habits {
+		if h.DeletedAt != nil {
+			continue
+		}
+		ch := CalendarHabit{Habit: h, Start: startOfDay(h.CreatedAt, loc).Format("2006-01-02")}
+		if h.ArchivedAt != nil {
+			ch.Until = startOfDay(*h.ArchivedAt, loc).Format("2006-01-02")
+		}
+		from := since
+		if ch.Start > from {
+			from = ch.Start
+		}
+		// привычка ушла в архив раньше, чем начинается история ленты
+		if ch.Until != "" && ch.Until < from {
+			cal.Habits = append(cal.Habits, ch)
+			continue
+		}
+		r, err := s.checkins.Report(user.Email, h.ID, from, "")
+		if err != nil {
+			return nil, err
+		}
+		done := doneDays(h, r.DailyTotals)
+		for day, amount := range r.DailyTotals {
+			if done[day] {
+				ch.Done = append(ch.Done, CalendarDay{Date: day, Amount: amount})
+			}
+		}
+		sort.Slice(ch.Done, func(i, j int) bool { return ch.Done[i].Date < ch.Done[j].Date })
+		cal.Habits = append(cal.Habits, ch)
+	}
+	return cal, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// Dashboard — сводка для главного экрана: все активные привычки пользователя
+type Dashboard struct {
+	Date   string           `json:"date"` // сегодня (YYYY-MM-DD) в поясе пользователя
+	Habits []DashboardHabit `json:"habits"`
+
+	// Сколько привычек нужно отметить сегодня, сколько отмечено и процент
+	DueToday            int     `json:"due_today"`
+	DoneToday           int     `json:"done_today"`
+	TodayCompletionRate float64 `json:"today_completion_rate"`
+}
+
+// DashboardHabit — привычка со статусом на сегодня и основной статистикой
+type DashboardHabit struct {
+	Habit          *domain.Habit `json:"habit"`
+	DueToday       bool          `json:"due_today"`
+	DoneToday      bool          `json:"done_today"`
+	TodayAmount    float64       `json:"today_amount"`
+	Streak         int           `json:"streak"`
+	AtRisk         bool          `json:"at_risk"`
+	CompletionRate float64       `json:"completion_rate"`
+}
+
+// Dashboard собирает сводку по всем активным привычкам пользователя;
+// отметки всех привычек читаются из хранилища за одно обращение
+func (s *HabitCheckinService) Dashboard(userEmail string) (*Dashboard, error) {
+	habits, err := s.habitRepo.FindAllByUser(userEmail)
+	if err != nil {
+		return nil, err
+	}
+	var active []*domain.Habit
+	ids := make([]string, 0, len(habits))
+	for _, h := range habits {
+		if h.Status() == domain.HabitActive {
+			active = append(active, h)
+			ids = append(ids, h.ID)
+		}
+	}
+	// порядок как в списке создания, независимо от хранилища
+	sort.Slice(active, func(i, j int) bool { return active[i].CreatedAt.Before(active[j].CreatedAt) })
+	checks, err := s.checkinRepo.FindByHabits(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := userLocation(s.userRepo, userEmail)
+	now := time.Now()
+	today := startOfDay(now, loc).Format("2006-01-02")
+	d := &Dashboard{Date: today, Habits: make([]DashboardHabit, 0, len(active))}
+	for _, h := range active {
+		r, err := buildReport(h, checks[h.ID], loc, now, "", "")
+		if err != nil {
+			return nil, err
+		}
+		d.Habits = append(d.Habits, DashboardHabit{
+			Habit:          h,
+			DueToday:       r.dueToday,
+			DoneToday:      r.doneToday,
+			TodayAmount:    r.DailyTotals[today],
+			Streak:         r.Streak,
+			AtRisk:         r.AtRisk,
+			CompletionRate: r.CompletionRate,
+		})
+		if r.dueToday {
+			d.DueToday++
+			if r.doneToday {
+				d.DoneToday++
+			}
+		}
+	}
+	if d.DueToday > 0 {
+		d.TodayCompletionRate = float64(d.DoneToday) / float64(d.DueToday) * 100
+	}
+	return d, nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// ExportVersion — версия формата JSON-выгрузки (data.json)
+const ExportVersion = 1
+
+// ExportUser — профиль в выгрузке (без пароля)
+type ExportUser struct {
+	Email     string    `json:"email"`
+	Timezone  string    `json:"timezone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportHabit — привычка в выгрузке вместе с отметками
+type ExportHabit struct {
+	domain.Habit
+	Checkins []ExportCheckin `json:"checkins"`
+}
+
+// ExportCheckin — отметка в выгрузке; день — YYYY-MM-DD в поясе пользователя
+type ExportCheckin struct {
+	Date    string  `json:"date"`
+	Amount  float64 `json:"amount"`
+	Comment string  `json:"comment,omitempty"`
+}
+
+// ExportData — формат data.json целиком (нужен для разбора при импорте)
+type ExportData struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exported_at"`
+	User       ExportUser    `json:"user"`
+	Habits     []ExportHabit `json:"habits"`
+}
+
+// ExportService выгружает все данные пользователя
+type ExportService struct {
+	habitRepo   HabitStore
+	checkinRepo CheckinStore
+	userRepo    UserStore
+}
+
+func NewExportService(hr HabitStore, cr CheckinStore, ur UserStore) *ExportService {
+	return &ExportService{hr, cr, ur}
+}
+
+// Export пишет в w ZIP-архив с habits.csv, checkins.csv и data.json.
+// Отметки читаются по одной привычке, поэтому все данные в памяти не держатся.
+func (s *ExportService) Export(userEmail string, w io.Writer) error {
+	user, err := s.userRepo.FindByEmail(userEmail)
+	if err != nil {
+		return err
+	}
+	habits, err := s.habitRepo.FindAllByUser(userEmail)
+	if err != nil {
+		return err
+	}
+	sort.Slice(habits, func(i, j int) bool { return habits[i].CreatedAt.Before(habits[j].CreatedAt) })
+
+	zw := zip.NewWriter(w)
+	if err := s.writeHabitsCSV(zw, habits); err != nil {
+		return err
+	}
+	if err := s.writeCheckinsCSV(zw, habits); err != nil {
+		return err
+	}
+	if err := s.writeJSON(zw, user, habits); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *ExportService) writeHabitsCSV(zw *zip.Writer, habits []*domain.Habit) error {
+	f, err := zw.Create("habits.csv")
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	cw.Write([]string{"id", "name", "goal", "schedule_type", "weekdays", "times", "interval",
+		"target", "unit", "status", "created_at", "archived_at", "deleted_at", "freeze_days"})
+	for _, h := range habits {
+		weekdays := make([]string, len(h.Schedule.Weekdays))
+		for i, d := range h.Schedule.Weekdays {
+			weekdays[i] = strconv.Itoa(int(d))
+		}
+		cw.Write([]string{
+			h.ID, h.Name, h.Goal, string(h.Schedule.Type), strings.Join(weekdays, " "),
+			strconv.Itoa(h.Schedule.Times), strconv.Itoa(h.Schedule.Interval),
+			strconv.FormatFloat(h.Target, 'f', -1, 64), h.Unit, string(h.Status()),
+			h.CreatedAt.Format(time.RFC3339), formatOptionalTime(h.ArchivedAt), formatOptionalTime(h.DeletedAt),
+			strings.Join(h.FreezeDays, " "),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (s *ExportService) writeCheckinsCSV(zw *zip.Writer, habits []*domain.Habit) error {
+	f, err := zw.Create("checkins.csv")
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	cw.Write([]string{"habit_id", "habit_name", "date", "amount", "comment"})
+	for _, h := range habits {
+		checks, err := s.sortedCheckins(h.ID)
+		if err != nil {
+			return err
+		}
+		for _, c := range checks {
+			cw.Write([]string{h.ID, h.Name, c.Date, strconv.FormatFloat(c.Amount, 'f', -1, 64), c.Comment})
+		}
+		// сбрасываем буфер после каждой привычки, чтобы данные уходили клиенту
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSON пишет data.json по частям: шапку, затем привычки по одной
+func (s *ExportService) writeJSON(zw *zip.Writer, user *domain.User, habits []*domain.Habit) error {
+	f, err := zw.Create("data.json")
+	if err != nil {
+		return err
+	}
+	head, err := json.Marshal(ExportData{
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		User:       ExportUser{Email: user.Email, Timezone: user.Timezone, CreatedAt: user.CreatedAt},
+	})
+	if err != nil {
+		return err
+	}
+	// шапка заканчивается на "habits":null} — подставляем вместо null массив
+	prefix := strings.TrimSuffix(string(head), "null}")
+	if _, err := io.WriteString(f, prefix+"["); err != nil {
+		return err
+	}
+	for i, h := range habits {
+		checks, err := s.sortedCheckins(h.ID)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(ExportHabit{Habit: *h, Checkins: checks})
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := io.WriteString(f, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(f, "]}\n")
+	return err
+}
+
+// sortedCheckins — отметки привычки по возрастанию дня
+func (s *ExportService) sortedCheckins(habitID string) ([]ExportCheckin, error) {
+	checks, err := s.checkinRepo.FindByHabit(habitID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]ExportCheckin, len(checks))
+	for i, c := range checks {
+		res[i] = ExportCheckin{Date: c.Date.Format("2006-01-02"), Amount: c.AmountOrOne(), Comment: c.Comment}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Date < res[j].Date })
+	return res, nil
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -3,103 +3,347 @@ package service
 import (
 	"errors"
 	"habit-tracker-api/internal/domain"
-	"habit-tracker-api/internal/repository"
+	"sort"
 	"time"
 )
 
 type HabitCheckinService struct {
-	habitRepo   *repository.HabitRepository
-	checkinRepo *repository.HabitCheckinRepository
+	habitRepo   HabitStore
+	checkinRepo CheckinStore
+	userRepo    UserStore // для часового пояса владельца привычки
 }
 
 func NewHabitCheckinService(
-	hr *repository.HabitRepository,
-	cr *repository.HabitCheckinRepository,
+	hr HabitStore,
+	cr CheckinStore,
+	ur UserStore,
 ) *HabitCheckinService {
-	return &HabitCheckinService{hr, cr}
+	return &HabitCheckinService{hr, cr, ur}
 }
 
-// CheckIn отмечает выполнение привычки на сегодня (или в указанную дату)
-func (s *HabitCheckinService) CheckIn(habitID, comment string) error {
-	// Проверяем существование привычки
-	if _, err := s.habitRepo.FindByID(habitID); err != nil {
-		return errors.New("habit not found")
+// CheckIn отмечает выполнение привычки на сегодня (или в указанную дату).
+// date — день в формате YYYY-MM-DD в поясе владельца, пустой — сегодня;
+// amount — сколько сделано, для привычек без цели достаточно 1.
+func (s *HabitCheckinService) CheckIn(userEmail, habitID, date, comment string, amount float64) error {
+	// Проверяем существование и владельца привычки (архивные не отмечаются)
+	h, err := activeHabit(s.habitRepo, userEmail, habitID)
+	if err != nil {
+		return err
+	}
+	if amount < 0 {
+		return errors.New("amount must be positive")
 	}
+	if amount == 0 {
+		amount = 1
+	}
+
+	// день считается в часовом поясе владельца привычки
+	loc := userLocation(s.userRepo, h.UserEmail)
+	today := startOfDay(time.Now(), loc)
+	day := today
+	if date != "" {
+		day, err = time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil {
+			return errors.New("invalid date")
+		}
+		if day.After(today) {
+			return errors.New("date is in the future")
+		}
+		if day.Before(startOfDay(h.CreatedAt, loc)) {
+			return errors.New("date is before habit creation")
+		}
+	}
+
 	hc := &domain.HabitCheckin{
 		HabitID: habitID,
-		// Truncate обнуляет время, оставляя только дату
-		Date:    time.Now().Truncate(24 * time.Hour),
+		Date:    day,
+		Amount:  amount,
 		Comment: comment,
 	}
 	return s.checkinRepo.Create(hc)
 }
 
-// Stats возвращает статистику по привычке:
-// streak — дней подряд до сегодня,
-// totalChecks — уникальных дней выполнения,
-// possibleChecks — дней с момента создания,
-// completionRate — процент выполнения
-func (s *HabitCheckinService) Stats(habitID string) (
-	streak int, totalChecks, possibleChecks int, completionRate float64, err error,
-) {
-	// Получаем все отметки
-	checks, err := s.checkinRepo.FindByHabit(habitID)
-	if err != nil {
-		return
+// ListCheckins возвращает отметки привычки по возрастанию даты;
+// from и to (YYYY-MM-DD, включительно) необязательны
+func (s *HabitCheckinService) ListCheckins(userEmail, habitID, from, to string) ([]domain.HabitCheckin, error) {
+	if _, err := ownedHabit(s.habitRepo, userEmail, habitID); err != nil {
+		return nil, err
 	}
-	// Узнаём дату создания привычки
-	h, err := s.habitRepo.FindByID(habitID)
+	if from != "" && !isDay(from) {
+		return nil, errors.New("invalid from")
+	}
+	if to != "" && !isDay(to) {
+		return nil, errors.New("invalid to")
+	}
+	checks, err := s.checkinRepo.FindByHabit(habitID)
 	if err != nil {
-		return
+		return nil, err
 	}
-	start := h.CreatedAt.Truncate(24 * time.Hour)
-	today := time.Now().Truncate(24 * time.Hour)
-
-	// Считаем, сколько дней всего прошло
-	days := int(today.Sub(start).Hours()/24) + 1
-	possibleChecks = days
-
-	// Собираем уникальные даты выполнения
-	done := make(map[string]bool, len(checks))
+	res := make([]domain.HabitCheckin, 0, len(checks))
 	for _, c := range checks {
-		key := c.Date.Format("2006-01-02")
-		done[key] = true
+		day := c.Date.Format("2006-01-02")
+		if (from != "" && day < from) || (to != "" && day > to) {
+			continue
+		}
+		res = append(res, c)
 	}
-	totalChecks = len(done)
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Date.Before(res[j].Date)
+	})
+	return res, nil
+}
 
-	// Считаем текущий streak
-	streak = 0
-	for d := today; !d.Before(start); d = d.AddDate(0, 0, -1) {
-		if done[d.Format("2006-01-02")] {
-			streak++
-		} else {
-			break
+// UpdateCheckin правит комментарий и/или количество отметки за день.
+// nil-поля не меняются.
+func (s *HabitCheckinService) UpdateCheckin(
+	userEmail, habitID, date string, comment *string, amount *float64,
+) (*domain.HabitCheckin, error) {
+	if _, err := activeHabit(s.habitRepo, userEmail, habitID); err != nil {
+		return nil, err
+	}
+	if !isDay(date) {
+		return nil, errors.New("invalid date")
+	}
+	hc, err := s.checkinRepo.FindByHabitAndDate(habitID, date)
+	if err != nil {
+		return nil, err
+	}
+	if comment != nil {
+		hc.Comment = *comment
+	}
+	if amount != nil {
+		if *amount <= 0 {
+			return nil, errors.New("amount must be positive")
 		}
+		hc.Amount = *amount
+	}
+	if err := s.checkinRepo.Update(hc); err != nil {
+		return nil, err
 	}
+	return hc, nil
+}
 
-	// Процент выполнения
-	completionRate = float64(totalChecks) / float64(possibleChecks) * 100
-	return
+// DeleteCheckin удаляет отметку за день (например, случайную)
+func (s *HabitCheckinService) DeleteCheckin(userEmail, habitID, date string) error {
+	if _, err := activeHabit(s.habitRepo, userEmail, habitID); err != nil {
+		return err
+	}
+	if !isDay(date) {
+		return errors.New("invalid date")
+	}
+	return s.checkinRepo.Delete(habitID, date)
+}
+
+// isDay проверяет формат даты YYYY-MM-DD
+func isDay(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+// Stats возвращает статистику по привычке с учётом её расписания:
+// streak — периодов расписания подряд до сегодня (дней для ежедневных),
+// totalChecks — засчитанных отметок,
+// possibleChecks — требуемых расписанием отметок с момента создания,
+// completionRate — процент выполнения
+func (s *HabitCheckinService) Stats(userEmail, habitID string) (
+	streak int, totalChecks, possibleChecks int, completionRate float64, err error,
+) {
+	r, err := s.Report(userEmail, habitID, "", "")
+	if err != nil {
+		return
+	}
+	return r.Streak, r.TotalChecks, r.PossibleChecks, r.CompletionRate, nil
 }
 
 // Добавление Report
 
 type HabitReport struct {
+	// Диапазон отчёта (YYYY-MM-DD): с создания привычки по сегодня, если не задан
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Текущая серия; пока сегодняшний период не закрыт, он серию не прерывает,
+	// а AtRisk показывает, что без отметки сегодня серия оборвётся
 	Streak         int     `json:"streak"`
+	AtRisk         bool    `json:"at_risk"`
 	TotalChecks    int     `json:"total_checks"`
 	PossibleChecks int     `json:"possible_checks"`
 	CompletionRate float64 `json:"completion_rate"`
+
+	// Все серии выполнения в диапазоне и самая длинная из них
+	LongestStreak int         `json:"longest_streak"`
+	Streaks       []StreakRun `json:"streaks"`
+
+	// Выполнение по календарным неделям (с понедельника) и месяцам
+	Weekly   []PeriodRate `json:"weekly"`
+	Monthly  []PeriodRate `json:"monthly"`
+	BestWeek *PeriodRate  `json:"best_week,omitempty"`
+
+	// Для количественных привычек: цель на день и суммы по дням (YYYY-MM-DD)
+	Target      float64            `json:"target,omitempty"`
+	Unit        string             `json:"unit,omitempty"`
+	DailyTotals map[string]float64 `json:"daily_totals"`
+
+	dueToday  bool // сегодня по расписанию нужна отметка
+	doneToday bool // сегодня дневная цель выполнена
 }
 
-func (s *HabitCheckinService) Report(habitID string) (*HabitReport, error) {
-	streak, total, possible, rate, err := s.Stats(habitID)
+// Report считает отчёт по привычке за диапазон from–to (YYYY-MM-DD, включительно,
+// оба необязательны). Текущий streak — серия, которая длится на конец диапазона.
+func (s *HabitCheckinService) Report(userEmail, habitID, from, to string) (*HabitReport, error) {
+	// Узнаём дату создания, расписание и цель привычки
+	h, err := ownedHabit(s.habitRepo, userEmail, habitID)
 	if err != nil {
 		return nil, err
 	}
-	return &HabitReport{
-		Streak:         streak,
-		TotalChecks:    total,
-		PossibleChecks: possible,
-		CompletionRate: rate,
-	}, nil
+	// Получаем все отметки
+	checks, err := s.checkinRepo.FindByHabit(habitID)
+	if err != nil {
+		return nil, err
+	}
+	return buildReport(h, checks, userLocation(s.userRepo, h.UserEmail), time.Now(), from, to)
+}
+
+// buildReport считает отчёт по уже загруженным привычке и её отметкам
+// на момент now; дни определяются в поясе loc
+func buildReport(h *domain.Habit, checks []domain.HabitCheckin, loc *time.Location, now time.Time, from, to string) (*HabitReport, error) {
+	var err error
+	start := startOfDay(h.CreatedAt, loc)
+	today := startOfDay(now, loc)
+	end := today
+	// Архивная привычка на паузе: статистика считается по день архивации
+	if h.ArchivedAt != nil {
+		end = startOfDay(*h.ArchivedAt, loc)
+	}
+	rangeFrom := start
+	if from != "" {
+		if rangeFrom, err = time.ParseInLocation("2006-01-02", from, loc); err != nil {
+			return nil, errors.New("invalid from")
+		}
+	}
+	if to != "" {
+		rangeTo, err := time.ParseInLocation("2006-01-02", to, loc)
+		if err != nil {
+			return nil, errors.New("invalid to")
+		}
+		if rangeTo.Before(end) {
+			end = rangeTo
+		}
+	}
+	if rangeFrom.After(end) {
+		return nil, errors.New("from must not be after to")
+	}
+
+	report := &HabitReport{
+		From:        rangeFrom.Format("2006-01-02"),
+		To:          end.Format("2006-01-02"),
+		Target:      h.Target,
+		Unit:        h.Unit,
+		DailyTotals: dailyTotals(checks),
+	}
+	done := doneDays(h, report.DailyTotals)
+	for day := range report.DailyTotals {
+		if day < report.From || day > report.To {
+			delete(report.DailyTotals, day)
+		}
+	}
+
+	// Периоды считаются от создания привычки, чтобы границы окон
+	// не зависели от диапазона; в отчёт идут пересекающиеся с ним
+	var periods []period
+	for _, p := range schedulePeriods(h.Schedule, start, end) {
+		if !p.to.Before(rangeFrom) {
+			periods = append(periods, p)
+		}
+	}
+	frozen := make(map[string]bool, len(h.FreezeDays))
+	for _, day := range h.FreezeDays {
+		frozen[day] = true
+	}
+	results := make([]periodResult, len(periods))
+	for i, p := range periods {
+		count, free := 0, 0
+		for d := p.from; !d.After(p.to); d = d.AddDate(0, 0, 1) {
+			day := d.Format("2006-01-02")
+			if done[day] {
+				count++
+			} else if frozen[day] {
+				free++
+			}
+		}
+		// Дни отдыха уменьшают требование периода: нельзя требовать
+		// больше отметок, чем осталось рабочих дней
+		if days := calendarDays(p.from, p.to); p.required > days-free {
+			p.required = days - free
+		}
+		if count > p.required {
+			count = p.required
+		}
+		r := periodResult{period: p, count: count, satisfied: p.required > 0 && count == p.required}
+		r.frozen = p.required == 0
+
+		// Незакрытый период (сегодняшний день, текущая неделя…)
+		// не штрафует, пока в нём ещё можно успеть
+		r.open = !r.satisfied && (p.to.After(end) || !p.to.Before(today))
+		if r.open {
+			r.possible = count
+		} else {
+			r.possible = p.required
+		}
+		results[i] = r
+		report.PossibleChecks += r.possible
+		report.TotalChecks += count
+	}
+
+	report.Streaks = streakRuns(results, end)
+	for _, run := range report.Streaks {
+		if run.Length > report.LongestStreak {
+			report.LongestStreak = run.Length
+		}
+	}
+	// Текущий streak — последняя серия, если после неё нет пропущенных периодов
+	if n := len(report.Streaks); n > 0 && report.Streaks[n-1].current {
+		report.Streak = report.Streaks[n-1].Length
+	}
+	if report.Streak > 0 && end.Equal(today) {
+		report.AtRisk = atRisk(results[len(results)-1], today, done, frozen)
+	}
+	// Статус на сегодня (для сводки): привычка «на сегодня», если текущий
+	// период ещё требует отметок или уже закрыт сегодняшней отметкой
+	if n := len(results); n > 0 && end.Equal(today) {
+		key := today.Format("2006-01-02")
+		report.doneToday = done[key]
+		if last := results[n-1]; !last.frozen && !last.to.Before(today) {
+			report.dueToday = last.open || report.doneToday
+		}
+	}
+
+	report.Weekly, report.Monthly = periodRates(results)
+	report.BestWeek = bestWeek(report.Weekly)
+
+	// Процент выполнения
+	if report.PossibleChecks > 0 {
+		report.CompletionRate = float64(report.TotalChecks) / float64(report.PossibleChecks) * 100
+	}
+	return report, nil
+}
+
+// dailyTotals суммирует количество по дням (YYYY-MM-DD)
+func dailyTotals(checks []domain.HabitCheckin) map[string]float64 {
+	totals := make(map[string]float64, len(checks))
+	for _, c := range checks {
+		totals[c.Date.Format("2006-01-02")] += c.AmountOrOne()
+	}
+	return totals
+}
+
+// doneDays возвращает дни, в которые набрана дневная цель привычки
+func doneDays(h *domain.Habit, totals map[string]float64) map[string]bool {
+	done := make(map[string]bool, len(totals))
+	for day, total := range totals {
+		if total >= h.Target {
+			done[day] = true
+		}
+	}
+	return done
 }
@@ -3,58 +3,229 @@ package service
 import (
 	"errors"
 	"habit-tracker-api/internal/domain"
-	"habit-tracker-api/internal/repository"
+	"sort"
 	"strings"
 	"time"
 )
 
 type HabitService struct {
-	repo *repository.HabitRepository
+	repo     HabitStore
+	userRepo UserStore // для часового пояса в фильтрах по датам
 }
 
-func NewHabitService(r *repository.HabitRepository) *HabitService {
-	return &HabitService{r}
+func NewHabitService(r HabitStore, ur UserStore) *HabitService {
+	return &HabitService{r, ur}
 }
 
 func (s *HabitService) Create(h *domain.Habit) error {
-	if h.Name == "" {
-		return errors.New("habit name is required")
+	if err := validateHabit(h); err != nil {
+		return err
 	}
 	return s.repo.Create(h)
 }
 
+// validateHabit — общие правила для созданных, изменённых и импортированных
+// привычек; пустое расписание становится ежедневным
+func validateHabit(h *domain.Habit) error {
+	if h.Name == "" {
+		return invalidInput("habit name is required")
+	}
+	if err := validateSchedule(&h.Schedule); err != nil {
+		return err
+	}
+	if h.Target < 0 {
+		return invalidInput("habit target must not be negative")
+	}
+	return nil
+}
+
 func (s *HabitService) GetAll(userEmail string) ([]*domain.Habit, error) {
 	return s.repo.FindAllByUser(userEmail)
 }
 
-func (s *HabitService) GetByID(id string) (*domain.Habit, error) {
-	return s.repo.FindByID(id)
+// GetByID возвращает привычку, если она принадлежит userEmail
+func (s *HabitService) GetByID(userEmail, id string) (*domain.Habit, error) {
+	return ownedHabit(s.repo, userEmail, id)
 }
 
-func (s *HabitService) Update(h *domain.Habit) error {
+// Update сохраняет изменения привычки; владельца сменить нельзя
+func (s *HabitService) Update(userEmail string, h *domain.Habit) error {
 	if h.ID == "" {
 		return errors.New("habit ID is required")
 	}
+	if _, err := ownedHabit(s.repo, userEmail, h.ID); err != nil {
+		return err
+	}
+	h.UserEmail = userEmail
+	if err := validateHabit(h); err != nil {
+		return err
+	}
 	return s.repo.Update(h)
 }
 
-func (s *HabitService) Delete(id string) error {
+// Delete переносит привычку в корзину: её можно восстановить,
+// пока корзину не очистит фоновая задача
+func (s *HabitService) Delete(userEmail, id string) error {
+	h, err := ownedHabit(s.repo, userEmail, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	h.DeletedAt = &now
+	return s.repo.Update(h)
+}
+
+// DeletePermanently удаляет привычку (в том числе из корзины) вместе с отметками
+func (s *HabitService) DeletePermanently(userEmail, id string) error {
+	if _, err := ownedHabitWithTrash(s.repo, userEmail, id); err != nil {
+		return err
+	}
 	return s.repo.Delete(id)
 }
 
-// ListHabits — возвращает отфильтрованный и постраничный список привычек пользователя
+// Restore возвращает привычку из корзины
+func (s *HabitService) Restore(userEmail, id string) (*domain.Habit, error) {
+	h, err := ownedHabitWithTrash(s.repo, userEmail, id)
+	if err != nil {
+		return nil, err
+	}
+	if h.DeletedAt == nil {
+		return nil, errors.New("habit is not in trash")
+	}
+	h.DeletedAt = nil
+	if err := s.repo.Update(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Archive ставит привычку на паузу: история сохраняется,
+// новые отметки не принимаются, статистика замирает на дне архивации
+func (s *HabitService) Archive(userEmail, id string) (*domain.Habit, error) {
+	h, err := ownedHabit(s.repo, userEmail, id)
+	if err != nil {
+		return nil, err
+	}
+	if h.ArchivedAt == nil {
+		now := time.Now()
+		h.ArchivedAt = &now
+		if err := s.repo.Update(h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// Unarchive возвращает привычку из архива
+func (s *HabitService) Unarchive(userEmail, id string) (*domain.Habit, error) {
+	h, err := ownedHabit(s.repo, userEmail, id)
+	if err != nil {
+		return nil, err
+	}
+	if h.ArchivedAt != nil {
+		h.ArchivedAt = nil
+		if err := s.repo.Update(h); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+// AddFreeze добавляет день отдыха (YYYY-MM-DD): в этот день привычку
+// можно пропустить без потери серии. Планировать можно и на будущее.
+func (s *HabitService) AddFreeze(userEmail, id, date string) (*domain.Habit, error) {
+	h, err := ownedHabit(s.repo, userEmail, id)
+	if err != nil {
+		return nil, err
+	}
+	loc := userLocation(s.userRepo, userEmail)
+	day, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return nil, errors.New("invalid date")
+	}
+	if day.Before(startOfDay(h.CreatedAt, loc)) {
+		return nil, errors.New("date is before habit creation")
+	}
+	i := sort.SearchStrings(h.FreezeDays, date)
+	if i < len(h.FreezeDays) && h.FreezeDays[i] == date {
+		return h, nil
+	}
+	h.FreezeDays = append(h.FreezeDays, "")
+	copy(h.FreezeDays[i+1:], h.FreezeDays[i:])
+	h.FreezeDays[i] = date
+	if err := s.repo.Update(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// RemoveFreeze убирает день отдыха
+func (s *HabitService) RemoveFreeze(userEmail, id, date string) (*domain.Habit, error) {
+	h, err := ownedHabit(s.repo, userEmail, id)
+	if err != nil {
+		return nil, err
+	}
+	i := sort.SearchStrings(h.FreezeDays, date)
+	if i == len(h.FreezeDays) || h.FreezeDays[i] != date {
+		return nil, errors.New("freeze day not found")
+	}
+	h.FreezeDays = append(h.FreezeDays[:i], h.FreezeDays[i+1:]...)
+	if err := s.repo.Update(h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// PurgeTrash окончательно удаляет привычки, пролежавшие в корзине дольше retention.
+// Возвращает число удалённых.
+func (s *HabitService) PurgeTrash(retention time.Duration) (int, error) {
+	cutoff := time.Now().Add(-retention)
+	var expired []string
+	err := s.repo.ForEach(func(h *domain.Habit) error {
+		if h.DeletedAt != nil && h.DeletedAt.Before(cutoff) {
+			expired = append(expired, h.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	// удаляем после обхода: менять хранилище во время ForEach нельзя
+	for i, id := range expired {
+		if err := s.repo.Delete(id); err != nil {
+			return i, err
+		}
+	}
+	return len(expired), nil
+}
+
+// ListHabits — возвращает отфильтрованный и постраничный список привычек пользователя.
+// status — active (по умолчанию), archived, trashed или all (активные и архивные).
 func (s *HabitService) ListHabits(
-	userEmail, name, dateFrom, dateTo string,
+	userEmail, status, name, dateFrom, dateTo string,
 	page, pageSize int,
 ) ([]*domain.Habit, error) {
+	switch domain.HabitStatus(status) {
+	case "":
+		status = string(domain.HabitActive)
+	case domain.HabitActive, domain.HabitArchived, domain.HabitTrashed, "all":
+	default:
+		return nil, errors.New("invalid status")
+	}
 	all, err := s.repo.FindAllByUser(userEmail)
 	if err != nil {
 		return nil, err
 	}
 
+	// даты фильтра — дни в часовом поясе пользователя
+	loc := userLocation(s.userRepo, userEmail)
+
 	// фильтрация по имени и диапазону дат
 	var filtered []*domain.Habit
 	for _, h := range all {
+		if !matchesStatus(h, status) {
+			continue
+		}
 		if name != "" && !strings.Contains(
 			strings.ToLower(h.Name),
 			strings.ToLower(name),
@@ -62,7 +233,7 @@ func (s *HabitService) ListHabits(
 			continue
 		}
 		if dateFrom != "" {
-			from, err := time.Parse("2006-01-02", dateFrom)
+			from, err := time.ParseInLocation("2006-01-02", dateFrom, loc)
 			if err != nil {
 				return nil, errors.New("invalid date_from")
 			}
@@ -71,12 +242,12 @@ func (s *HabitService) ListHabits(
 			}
 		}
 		if dateTo != "" {
-			to, err := time.Parse("2006-01-02", dateTo)
+			to, err := time.ParseInLocation("2006-01-02", dateTo, loc)
 			if err != nil {
 				return nil, errors.New("invalid date_to")
 			}
 			// включаем всю дату dateTo
-			if h.CreatedAt.After(to.Add(24 * time.Hour)) {
+			if !h.CreatedAt.Before(to.AddDate(0, 0, 1)) {
 				continue
 			}
 		}
@@ -95,3 +266,12 @@ func (s *HabitService) ListHabits(
 	}
 	return filtered[start:end], nil
 }
+
+// matchesStatus — подходит ли привычка под фильтр status списка;
+// all — все, кроме корзины
+func matchesStatus(h *domain.Habit, status string) bool {
+	if status == "all" {
+		return h.Status() != domain.HabitTrashed
+	}
+	return string(h.Status()) == status
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"habit-tracker-api/internal/auth"
+	"habit-tracker-api/internal/domain"
+)
+
+// heatmapMaxDays — ограничение диапазона тепловой карты (около трёх лет)
+const heatmapMaxDays = 3 * 366
+
+// ErrHeatmapNotFound — токен тепловой карты неизвестен, карта закрыта
+// или привычки с таким ID у владельца токена нет
+var ErrHeatmapNotFound = errors.New("heatmap not found")
+
+// Heatmap — выполнение по дням для календаря в стиле GitHub
+type Heatmap struct {
+	From string       `json:"from"` // YYYY-MM-DD
+	To   string       `json:"to"`
+	Days []HeatmapDay `json:"days"` // каждый день диапазона по возрастанию
+}
+
+// HeatmapDay — выполнение за день: Value от 0 до 1 — доля дневной цели
+// (для привычек без цели — 1, если есть отметка); для пользователя — среднее
+// по привычкам, взвешенное тем, сколько отметок расписание ждёт в этот день
+type HeatmapDay struct {
+	Date   string  `json:"date"`
+	Value  float64 `json:"value"`
+	Total  float64 `json:"total,omitempty"`  // сумма количества (только для одной привычки)
+	Frozen bool    `json:"frozen,omitempty"` // день отдыха (только для одной привычки)
+	Rest   bool    `json:"rest,omitempty"`   // расписание в этот день ничего не ждало
+}
+
+// EnableHeatmap выпускает новый секретный токен публичной тепловой карты
+// и возвращает её адрес (SVG по всем привычкам; карта привычки —
+// адрес + "/habits/{id}.svg"). Прежний адрес перестаёт работать.
+func (s *UserService) EnableHeatmap(email string) (string, error) {
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return "", err
+	}
+	token, tokenHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	user.HeatmapToken = tokenHash
+	if err := s.repo.Update(user); err != nil {
+		return "", err
+	}
+	return s.appURL + "/heatmap/" + token, nil
+}
+
+// DisableHeatmap закрывает публичную тепловую карту
+func (s *UserService) DisableHeatmap(email string) error {
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	user.HeatmapToken = ""
+	return s.repo.Update(user)
+}
+
+// heatmapOwner — владелец публичной тепловой карты по токену из адреса
+func (s *HabitCheckinService) heatmapOwner(token string) (string, error) {
+	if token == "" {
+		return "", ErrHeatmapNotFound
+	}
+	user, err := s.userRepo.FindByHeatmapToken(auth.HashToken(token))
+	if err != nil {
+		return "", ErrHeatmapNotFound
+	}
+	return user.Email, nil
+}
+
+// PublicHeatmap — тепловая карта привычки по секретному токену владельца
+func (s *HabitCheckinService) PublicHeatmap(token, habitID, from, to string) (*Heatmap, error) {
+	email, err := s.heatmapOwner(token)
+	if err != nil {
+		return nil, err
+	}
+	hm, err := s.Heatmap(email, habitID, from, to)
+	// по публичному адресу не отличаем чужую привычку от несуществующей
+	if errors.Is(err, ErrHabitNotFound) || errors.Is(err, ErrForbidden) {
+		return nil, ErrHeatmapNotFound
+	}
+	return hm, err
+}
+
+// PublicUserHeatmap — тепловая карта по всем привычкам владельца токена
+func (s *HabitCheckinService) PublicUserHeatmap(token, from, to string) (*Heatmap, error) {
+	email, err := s.heatmapOwner(token)
+	if err != nil {
+		return nil, err
+	}
+	return s.UserHeatmap(email, from, to)
+}
+
+// Heatmap возвращает тепловую карту привычки за from–to (YYYY-MM-DD, включительно);
+// по умолчанию — последние 52 недели до сегодня
+func (s *HabitCheckinService) Heatmap(userEmail, habitID, from, to string) (*Heatmap, error) {
+	h, err := ownedHabit(s.habitRepo, userEmail, habitID)
+	if err != nil {
+		return nil, err
+	}
+	loc := userLocation(s.userRepo, h.UserEmail)
+	days, start, err := heatmapDays(from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := s.checkinRepo.FindByHabit(habitID)
+	if err != nil {
+		return nil, err
+	}
+	totals := dailyTotals(checks)
+	frozen := make(map[string]bool, len(h.FreezeDays))
+	for _, day := range h.FreezeDays {
+		frozen[day] = true
+	}
+	for i := range days {
+		d := &days[i]
+		d.Total = totals[d.Date]
+		d.Value = dayValue(h, d.Total)
+		d.Frozen = frozen[d.Date]
+		d.Rest = !d.Frozen && dueWeight(h, start.AddDate(0, 0, i)) == 0
+	}
+	return &Heatmap{From: days[0].Date, To: days[len(days)-1].Date, Days: days}, nil
+}
+
+// UserHeatmap — тепловая карта по всем активным привычкам пользователя:
+// значение дня — выполнение привычек, существовавших в этот день, взвешенное
+// по расписанию: привычка, которую в этот день делать не нужно, его не портит
+func (s *HabitCheckinService) UserHeatmap(userEmail, from, to string) (*Heatmap, error) {
+	loc := userLocation(s.userRepo, userEmail)
+	days, start, err := heatmapDays(from, to, loc)
+	if err != nil {
+		return nil, err
+	}
+	habits, err := s.habitRepo.FindAllByUser(userEmail)
+	if err != nil {
+		return nil, err
+	}
+	sum := make([]float64, len(days))
+	weight := make([]float64, len(days))
+	for _, h := range habits {
+		if h.Status() != domain.HabitActive {
+			continue
+		}
+		checks, err := s.checkinRepo.FindByHabit(h.ID)
+		if err != nil {
+			return nil, err
+		}
+		totals := dailyTotals(checks)
+		created := startOfDay(h.CreatedAt, loc).Format("2006-01-02")
+		frozen := make(map[string]bool, len(h.FreezeDays))
+		for _, day := range h.FreezeDays {
+			frozen[day] = true
+		}
+		for i, d := range days {
+			if d.Date < created || frozen[d.Date] {
+				continue
+			}
+			w := dueWeight(h, start.AddDate(0, 0, i))
+			sum[i] += w * dayValue(h, totals[d.Date])
+			weight[i] += w
+		}
+	}
+	for i := range days {
+		if weight[i] > 0 {
+			days[i].Value = sum[i] / weight[i]
+		} else {
+			days[i].Rest = true
+		}
+	}
+	return &Heatmap{From: days[0].Date, To: days[len(days)-1].Date, Days: days}, nil
+}
+
+// heatmapDays разбирает диапазон и возвращает пустые дни от from до to
+// и полночь первого из них
+func heatmapDays(from, to string, loc *time.Location) ([]HeatmapDay, time.Time, error) {
+	end := startOfDay(time.Now(), loc)
+	if to != "" {
+		var err error
+		if end, err = time.ParseInLocation("2006-01-02", to, loc); err != nil {
+			return nil, time.Time{}, errors.New("invalid to")
+		}
+	}
+	start := end.AddDate(0, 0, -7*52)
+	if from != "" {
+		var err error
+		if start, err = time.ParseInLocation("2006-01-02", from, loc); err != nil {
+			return nil, time.Time{}, errors.New("invalid from")
+		}
+	}
+	if start.After(end) {
+		return nil, time.Time{}, errors.New("from must not be after to")
+	}
+	if start.AddDate(0, 0, heatmapMaxDays).Before(end) {
+		return nil, time.Time{}, errors.New("date range is too long")
+	}
+	var days []HeatmapDay
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, HeatmapDay{Date: d.Format("2006-01-02")})
+	}
+	return days, start, nil
+}
+
+// dayValue — доля дневной цели привычки, от 0 до 1
+func dayValue(h *domain.Habit, total float64) float64 {
+	switch {
+	case total <= 0:
+		return 0
+	case h.Target <= 0 || total >= h.Target:
+		return 1
+	}
+	return total / h.Target
+}
+
+// dueWeight — сколько отметок расписание привычки ждёт в день d (полночь
+// в поясе пользователя): 1 для ежедневной, 0 или 1 для дней недели,
+// равная доля периода для «N раз в неделю/месяц» и «раз в N дней»
+func dueWeight(h *domain.Habit, d time.Time) float64 {
+	s := h.Schedule
+	switch s.Type {
+	case domain.ScheduleWeekdays:
+		for _, wd := range s.Weekdays {
+			if wd == d.Weekday() {
+				return 1
+			}
+		}
+		return 0
+	case domain.ScheduleTimesPerWeek:
+		return float64(s.Times) / 7
+	case domain.ScheduleTimesPerMonth:
+		last := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		return float64(s.Times) / float64(last.Day())
+	case domain.ScheduleEveryNDays:
+		if s.Interval > 0 {
+			return 1 / float64(s.Interval)
+		}
+	}
+	return 1
+}
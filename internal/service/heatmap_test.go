@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+	"habit-tracker-api/internal/repository/memory"
+)
+
+func TestDueWeight(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		schedule domain.Schedule
+		day      time.Time
+		want     float64
+	}{
+		{"daily", domain.Schedule{Type: domain.ScheduleDaily}, monday, 1},
+		{"weekday due", domain.Schedule{Type: domain.ScheduleWeekdays, Weekdays: []time.Weekday{time.Monday}}, monday, 1},
+		{"weekday not due", domain.Schedule{Type: domain.ScheduleWeekdays, Weekdays: []time.Weekday{time.Monday}}, monday.AddDate(0, 0, 1), 0},
+		{"times per week", domain.Schedule{Type: domain.ScheduleTimesPerWeek, Times: 7}, monday, 1},
+		{"times per month", domain.Schedule{Type: domain.ScheduleTimesPerMonth, Times: 31}, monday, 1},
+		{"every 2 days", domain.Schedule{Type: domain.ScheduleEveryNDays, Interval: 2}, monday, 0.5},
+	}
+	for _, tt := range tests {
+		if got := dueWeight(&domain.Habit{Schedule: tt.schedule}, tt.day); got != tt.want {
+			t.Errorf("%s: dueWeight = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Привычка, которую в этот день делать не нужно, не портит день пользователя
+func TestUserHeatmapWeightsBySchedule(t *testing.T) {
+	checkins := memory.NewCheckinStore()
+	habits := memory.NewHabitStore(checkins)
+	svc := NewHabitCheckinService(habits, checkins, memory.NewUserStore())
+
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	daily := &domain.Habit{UserEmail: "a@example.com", Name: "daily",
+		Schedule: domain.Schedule{Type: domain.ScheduleDaily}, CreatedAt: monday}
+	mondays := &domain.Habit{UserEmail: "a@example.com", Name: "mondays",
+		Schedule: domain.Schedule{Type: domain.ScheduleWeekdays, Weekdays: []time.Weekday{time.Monday}}, CreatedAt: monday}
+	for _, h := range []*domain.Habit{daily, mondays} {
+		if err := habits.Create(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, d := range []int{0, 1} {
+		if err := checkins.Create(&domain.HabitCheckin{HabitID: daily.ID, Date: monday.AddDate(0, 0, d), Amount: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hm, err := svc.UserHeatmap("a@example.com", "2026-10-12", "2026-10-13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []HeatmapDay{
+		{Date: "2026-10-12", Value: 0.5}, // понедельник: одна из двух
+		{Date: "2026-10-13", Value: 1.0}, // вторник: нужна только ежедневная
+	}
+	for i, d := range hm.Days {
+		if d != want[i] {
+			t.Errorf("day %d = %+v, want %+v", i, d, want[i])
+		}
+	}
+
+	hm, err = svc.Heatmap("a@example.com", mondays.ID, "2026-10-12", "2026-10-13")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hm.Days[0].Rest || !hm.Days[1].Rest {
+		t.Errorf("Rest = %v, %v, want false, true", hm.Days[0].Rest, hm.Days[1].Rest)
+	}
+}
+
+func TestPublicHeatmap(t *testing.T) {
+	users, _ := newTestUserService(t)
+	checkins := memory.NewCheckinStore()
+	habits := memory.NewHabitStore(checkins)
+	svc := NewHabitCheckinService(habits, checkins, users.repo)
+
+	own := &domain.Habit{UserEmail: "a@example.com", Name: "own", Schedule: domain.Schedule{Type: domain.ScheduleDaily}}
+	other := &domain.Habit{UserEmail: "b@example.com", Name: "other", Schedule: domain.Schedule{Type: domain.ScheduleDaily}}
+	for _, h := range []*domain.Habit{own, other} {
+		if err := habits.Create(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	url, err := users.EnableHeatmap("a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := path.Base(url)
+	rotated, err := users.EnableHeatmap("a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(rotated, "http://localhost/heatmap/") {
+		t.Fatalf("url = %s", rotated)
+	}
+	current := path.Base(rotated)
+
+	tests := []struct {
+		name    string
+		token   string
+		habitID string
+		want    error
+	}{
+		{"current token", current, "", nil},
+		{"current token, own habit", current, own.ID, nil},
+		{"current token, other user's habit", current, other.ID, ErrHeatmapNotFound},
+		{"current token, missing habit", current, "nope", ErrHeatmapNotFound},
+		{"rotated token", token, "", ErrHeatmapNotFound},
+		{"empty token", "", "", ErrHeatmapNotFound},
+	}
+	for _, tt := range tests {
+		var err error
+		if tt.habitID == "" {
+			_, err = svc.PublicUserHeatmap(tt.token, "", "")
+		} else {
+			_, err = svc.PublicHeatmap(tt.token, tt.habitID, "", "")
+		}
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if err := users.DisableHeatmap("a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.PublicUserHeatmap(current, "", ""); !errors.Is(err, ErrHeatmapNotFound) {
+		t.Errorf("after disable: err = %v", err)
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// Форматы импорта
+const (
+	ImportFormatLoop = "loop" // выгрузка Loop Habit Tracker: ZIP или Checkmarks.csv
+	ImportFormatJSON = "json" // наша выгрузка: data.json или ZIP из GET /export
+)
+
+// MaxImportSize — предельный размер файла импорта и каждого файла внутри архива
+const MaxImportSize = 32 << 20
+
+// ImportResult — что создано (или было бы создано в dry-run) при импорте
+type ImportResult struct {
+	DryRun          bool                `json:"dry_run"`
+	Format          string              `json:"format"`
+	Habits          []ImportHabitResult `json:"habits"`
+	CreatedHabits   int                 `json:"created_habits"`
+	CreatedCheckins int                 `json:"created_checkins"`
+	Conflicts       int                 `json:"conflicts"`
+	SkippedHabits   int                 `json:"skipped_habits"` // привычки из корзины
+}
+
+// ImportHabitResult — итог по одной привычке из файла
+type ImportHabitResult struct {
+	Name      string `json:"name"`
+	ID        string `json:"id,omitempty"`         // ID созданной привычки (не в dry-run)
+	CreatedAt string `json:"created_at,omitempty"` // YYYY-MM-DD
+	Checkins  int    `json:"checkins"`             // сколько отметок будет создано
+	Skipped   int    `json:"skipped_checkins"`     // отметки в будущем и с неверными данными
+	Status    string `json:"status"`               // create (dry-run) | created | conflict | skipped
+	Conflict  string `json:"conflict,omitempty"`   // почему привычка пропущена
+}
+
+// importedHabit — привычка из файла вместе с историей (дни — YYYY-MM-DD)
+type importedHabit struct {
+	habit    domain.Habit
+	checkins []ExportCheckin
+}
+
+// ImportService переносит привычки и историю отметок из файлов других приложений
+type ImportService struct {
+	habitRepo   HabitStore
+	checkinRepo CheckinStore
+	userRepo    UserStore
+}
+
+func NewImportService(hr HabitStore, cr CheckinStore, ur UserStore) *ImportService {
+	return &ImportService{hr, cr, ur}
+}
+
+// Import разбирает файл (format пустой — определить по содержимому) и создаёт
+// привычки с исходными датами создания и их отметки. Привычки, чьё имя уже
+// занято, пропускаются как конфликт. Привычки из корзины не импортируются;
+// архивные остаются в архиве, дни отдыха переносятся как есть.
+// В dry-run ничего не записывается.
+func (s *ImportService) Import(userEmail string, data []byte, format string, dryRun bool) (*ImportResult, error) {
+	if format == "" {
+		format = detectImportFormat(data)
+	}
+	var (
+		habits []importedHabit
+		err    error
+	)
+	switch format {
+	case ImportFormatLoop:
+		habits, err = parseLoop(data)
+	case ImportFormatJSON:
+		habits, err = parseExportJSON(data)
+	default:
+		return nil, invalidInput("unknown import format")
+	}
+	if err != nil {
+		// файл уже в памяти: любая ошибка разбора — ошибка самого файла
+		return nil, invalidInput(err.Error())
+	}
+
+	existing, err := s.habitRepo.FindAllByUser(userEmail)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, h := range existing {
+		if h.DeletedAt == nil {
+			taken[strings.ToLower(h.Name)] = true
+		}
+	}
+
+	loc := userLocation(s.userRepo, userEmail)
+	now := time.Now()
+	today := startOfDay(now, loc)
+	res := &ImportResult{DryRun: dryRun, Format: format, Habits: []ImportHabitResult{}}
+	for _, ih := range habits {
+		h := ih.habit
+		h.ID = ""
+		h.UserEmail = userEmail
+		r := ImportHabitResult{Name: h.Name}
+		if h.DeletedAt != nil {
+			// удалённая при выгрузке привычка вернулась бы в корзину и ушла бы с очисткой
+			r.Status, r.Conflict = "skipped", "habit is in trash"
+			res.SkippedHabits++
+			res.Habits = append(res.Habits, r)
+			continue
+		}
+
+		checkins, skipped := importCheckins(ih.checkins, loc, today)
+		r.Checkins, r.Skipped = len(checkins), skipped
+		// дата создания — не позже первой отметки
+		if len(checkins) > 0 && (h.CreatedAt.IsZero() || checkins[0].Date.Before(startOfDay(h.CreatedAt, loc))) {
+			h.CreatedAt = checkins[0].Date
+		}
+		// даты из будущего (чужие часы, правленый файл) сдвигаем на сейчас:
+		// иначе отчёт начнётся позже, чем закончится
+		if h.CreatedAt.IsZero() || h.CreatedAt.After(now) {
+			h.CreatedAt = now
+		}
+		if h.ArchivedAt != nil && h.ArchivedAt.After(now) {
+			archivedAt := now
+			h.ArchivedAt = &archivedAt
+		}
+		r.CreatedAt = startOfDay(h.CreatedAt, loc).Format("2006-01-02")
+
+		switch err := validateHabit(&h); {
+		case err != nil:
+			r.Conflict = err.Error()
+		case taken[strings.ToLower(h.Name)]:
+			r.Conflict = "habit with this name already exists"
+		}
+		if r.Conflict != "" {
+			r.Status = "conflict"
+			res.Conflicts++
+			res.Habits = append(res.Habits, r)
+			continue
+		}
+		taken[strings.ToLower(h.Name)] = true
+
+		if dryRun {
+			r.Status = "create"
+		} else {
+			if err := s.habitRepo.Create(&h); err != nil {
+				return nil, fmt.Errorf("habit %q: %w", h.Name, err)
+			}
+			for i := range checkins {
+				checkins[i].HabitID = h.ID
+			}
+			if err := s.checkinRepo.CreateMany(checkins); err != nil {
+				// без истории привычка не нужна: удаляем, чтобы повторный импорт
+				// не счёл её имя занятым
+				if derr := s.habitRepo.Delete(h.ID); derr != nil {
+					return nil, fmt.Errorf("habit %q checkins: %w (rollback: %v)", h.Name, err, derr)
+				}
+				return nil, fmt.Errorf("habit %q checkins: %w", h.Name, err)
+			}
+			r.ID, r.Status = h.ID, "created"
+		}
+		res.CreatedHabits++
+		res.CreatedCheckins += len(checkins)
+		res.Habits = append(res.Habits, r)
+	}
+	return res, nil
+}
+
+// importCheckins переводит дни в отметки в поясе loc, по возрастанию даты;
+// будущие дни, неверные даты и неположительные количества пропускаются
+func importCheckins(in []ExportCheckin, loc *time.Location, today time.Time) (res []domain.HabitCheckin, skipped int) {
+	for _, c := range in {
+		day, err := time.ParseInLocation("2006-01-02", c.Date, loc)
+		if err != nil || day.After(today) || c.Amount <= 0 {
+			skipped++
+			continue
+		}
+		res = append(res, domain.HabitCheckin{Date: day, Amount: c.Amount, Comment: c.Comment})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Date.Before(res[j].Date) })
+	return res, skipped
+}
+
+// detectImportFormat определяет формат по содержимому: наш JSON или ZIP
+// с data.json — json, остальное (ZIP или CSV Loop) — loop
+func detectImportFormat(data []byte) string {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return ImportFormatJSON
+	}
+	if zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		for _, f := range zr.File {
+			if path.Base(f.Name) == "data.json" {
+				return ImportFormatJSON
+			}
+		}
+	}
+	return ImportFormatLoop
+}
+
+// parseExportJSON читает нашу выгрузку: data.json или ZIP, в котором он лежит
+func parseExportJSON(data []byte) ([]importedHabit, error) {
+	if zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		f := findZipFile(zr, "data.json")
+		if f == nil {
+			return nil, errors.New("data.json not found in archive")
+		}
+		if data, err = readZipFile(f); err != nil {
+			return nil, err
+		}
+	}
+	var export ExportData
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid export JSON: %w", err)
+	}
+	if export.Version < 1 || export.Version > ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d", export.Version)
+	}
+	habits := make([]importedHabit, len(export.Habits))
+	for i, eh := range export.Habits {
+		habits[i] = importedHabit{habit: eh.Habit, checkins: eh.Checkins}
+	}
+	return habits, nil
+}
+
+// findZipFile ищет файл по имени (в любом каталоге архива, ближайший к корню)
+func findZipFile(zr *zip.Reader, name string) *zip.File {
+	var found *zip.File
+	for _, f := range zr.File {
+		if path.Base(f.Name) != name {
+			continue
+		}
+		if found == nil || strings.Count(f.Name, "/") < strings.Count(found.Name, "/") {
+			found = f
+		}
+	}
+	return found
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	// размер в заголовке архива может врать — ограничиваем само чтение
+	data, err := io.ReadAll(io.LimitReader(rc, MaxImportSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImportSize {
+		return nil, fmt.Errorf("%s is too large", f.Name)
+	}
+	return data, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// Значения отметок в выгрузке Loop Habit Tracker для привычек «да/нет»
+const (
+	loopYesManual = 2 // отмечено пользователем
+	loopSkip      = 3 // день пропущен намеренно — у нас это день отдыха
+	// 1 — «засчитано автоматически» по частоте, 0 — нет, -1 — неизвестно: не импортируются
+)
+
+// loopHabit — строка Habits.csv
+type loopHabit struct {
+	habit     domain.Habit
+	numerical bool
+}
+
+// parseLoop читает выгрузку Loop: ZIP с Habits.csv и Checkmarks.csv
+// или один Checkmarks.csv (тогда все привычки — ежедневные «да/нет»)
+func parseLoop(data []byte) ([]importedHabit, error) {
+	var habitsCSV, checkmarksCSV []byte
+	if zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		f := findZipFile(zr, "Checkmarks.csv")
+		if f == nil {
+			return nil, errors.New("Checkmarks.csv not found in archive")
+		}
+		if checkmarksCSV, err = readZipFile(f); err != nil {
+			return nil, err
+		}
+		if f := findZipFile(zr, "Habits.csv"); f != nil {
+			if habitsCSV, err = readZipFile(f); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		checkmarksCSV = data
+	}
+
+	meta := map[string]loopHabit{}
+	if habitsCSV != nil {
+		var err error
+		if meta, err = parseLoopHabits(habitsCSV); err != nil {
+			return nil, fmt.Errorf("Habits.csv: %w", err)
+		}
+	}
+	return parseLoopCheckmarks(checkmarksCSV, meta)
+}
+
+// parseLoopHabits читает Habits.csv; колонки ищутся по заголовку,
+// потому что у разных версий Loop они отличаются
+func parseLoopHabits(data []byte) (map[string]loopHabit, error) {
+	rows, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("empty file")
+	}
+	col := map[string]int{}
+	for i, name := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	get := func(row []string, names ...string) string {
+		for _, n := range names {
+			if i, ok := col[n]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+		}
+		return ""
+	}
+	if _, ok := col["name"]; !ok {
+		return nil, errors.New("no Name column")
+	}
+
+	res := make(map[string]loopHabit, len(rows)-1)
+	for _, row := range rows[1:] {
+		lh := loopHabit{habit: domain.Habit{Name: get(row, "name")}}
+		lh.habit.Goal = get(row, "question")
+		if lh.habit.Goal == "" {
+			lh.habit.Goal = get(row, "description")
+		}
+		num, _ := strconv.Atoi(get(row, "frequencynumerator", "numrepetitions"))
+		den, _ := strconv.Atoi(get(row, "frequencydenominator", "interval"))
+		lh.habit.Schedule = loopSchedule(num, den)
+		lh.numerical = get(row, "type") == "1"
+		if lh.numerical {
+			lh.habit.Unit = get(row, "unit")
+			lh.habit.Target, _ = strconv.ParseFloat(get(row, "target value"), 64)
+		}
+		if strings.EqualFold(get(row, "archived?", "archived"), "true") {
+			// точную дату архивации Loop не выгружает — ставится при импорте
+			now := time.Now()
+			lh.habit.ArchivedAt = &now
+		}
+		res[lh.habit.Name] = lh
+	}
+	return res, nil
+}
+
+// loopSchedule переводит частоту Loop «num раз за den дней» в наше расписание
+func loopSchedule(num, den int) domain.Schedule {
+	switch {
+	case num <= 0 || den <= 0 || num >= den:
+		return domain.Schedule{Type: domain.ScheduleDaily}
+	case num == 1:
+		return domain.Schedule{Type: domain.ScheduleEveryNDays, Interval: den}
+	case den == 7:
+		return domain.Schedule{Type: domain.ScheduleTimesPerWeek, Times: num}
+	case den == 30 || den == 31:
+		return domain.Schedule{Type: domain.ScheduleTimesPerMonth, Times: num}
+	}
+	return domain.Schedule{Type: domain.ScheduleDaily}
+}
+
+// parseLoopCheckmarks читает общий Checkmarks.csv: колонка Date и по колонке на привычку
+func parseLoopCheckmarks(data []byte, meta map[string]loopHabit) ([]importedHabit, error) {
+	rows, err := readCSV(data)
+	if err != nil {
+		return nil, fmt.Errorf("Checkmarks.csv: %w", err)
+	}
+	if len(rows) == 0 || !strings.EqualFold(strings.TrimSpace(rows[0][0]), "date") {
+		return nil, errors.New("Checkmarks.csv: expected Date column first")
+	}
+	header := rows[0]
+	habits := make([]importedHabit, 0, len(header)-1)
+	cols := make([]int, 0, len(header)-1) // номер колонки для каждой привычки
+	for i, name := range header[1:] {
+		name = strings.TrimSpace(name)
+		// у Loop строки заканчиваются запятой — последняя колонка пустая
+		if name == "" {
+			continue
+		}
+		lh, ok := meta[name]
+		if !ok {
+			lh = loopHabit{habit: domain.Habit{Name: name, Schedule: domain.Schedule{Type: domain.ScheduleDaily}}}
+		}
+		habits = append(habits, importedHabit{habit: lh.habit})
+		cols = append(cols, i+1)
+	}
+
+	for _, row := range rows[1:] {
+		day := strings.TrimSpace(row[0])
+		for j, c := range cols {
+			if c >= len(row) {
+				continue
+			}
+			raw := strings.TrimSpace(row[c])
+			v, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				continue
+			}
+			h := &habits[j]
+			if meta[h.habit.Name].numerical {
+				// Loop хранит количества в тысячных; дробные значения уже в единицах
+				if !strings.Contains(raw, ".") {
+					v /= 1000
+				}
+				if v > 0 {
+					h.checkins = append(h.checkins, ExportCheckin{Date: day, Amount: v})
+				}
+				continue
+			}
+			switch int(v) {
+			case loopYesManual:
+				h.checkins = append(h.checkins, ExportCheckin{Date: day, Amount: 1})
+			case loopSkip:
+				h.habit.FreezeDays = append(h.habit.FreezeDays, day)
+			}
+		}
+	}
+	for i := range habits {
+		// дни отдыха храним по возрастанию, а Loop выгружает от новых к старым
+		sort.Strings(habits[i].habit.FreezeDays)
+	}
+	return habits, nil
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	return r.ReadAll()
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"habit-tracker-api/internal/domain"
+)
+
+func loopZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoopSchedule(t *testing.T) {
+	tests := []struct {
+		num, den int
+		want     domain.Schedule
+	}{
+		{1, 1, domain.Schedule{Type: domain.ScheduleDaily}},
+		{0, 0, domain.Schedule{Type: domain.ScheduleDaily}},
+		{7, 7, domain.Schedule{Type: domain.ScheduleDaily}},
+		{1, 3, domain.Schedule{Type: domain.ScheduleEveryNDays, Interval: 3}},
+		{1, 7, domain.Schedule{Type: domain.ScheduleEveryNDays, Interval: 7}},
+		{3, 7, domain.Schedule{Type: domain.ScheduleTimesPerWeek, Times: 3}},
+		{10, 30, domain.Schedule{Type: domain.ScheduleTimesPerMonth, Times: 10}},
+		{4, 31, domain.Schedule{Type: domain.ScheduleTimesPerMonth, Times: 4}},
+		{2, 5, domain.Schedule{Type: domain.ScheduleDaily}}, // у нас такого расписания нет
+	}
+	for _, tt := range tests {
+		if got := loopSchedule(tt.num, tt.den); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("loopSchedule(%d, %d) = %+v, want %+v", tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+const (
+	loopHabitsCSV = "Position,Name,Type,Question,Description,FrequencyNumerator,FrequencyDenominator,Color,Unit,Target Type,Target Value,Archived?\n" +
+		"001,Read,0,Did you read?,,3,7,#FF8F00,,0,0,false\n" +
+		"002,Water,1,How much water?,,1,1,#00897B,ml,0,2000,false\n" +
+		"003,Old,0,,Retired habit,1,2,#3949AB,,0,0,true\n"
+	// Loop выгружает дни от новых к старым, строки заканчиваются запятой
+	loopCheckmarksCSV = "Date,Read,Water,Old,\n" +
+		"2026-10-03,2,1500,3,\n" +
+		"2026-10-02,3,0.5,1,\n" +
+		"2026-10-01,1,0,-1,\n"
+)
+
+// Выгрузка Loop разбирается одинаково из ZIP и из одного Checkmarks.csv,
+// но расписания и количества берутся только из Habits.csv
+func TestParseLoop(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []importedHabit
+	}{
+		{
+			name: "archive",
+			data: loopZip(t, map[string]string{
+				"Habits.csv":              loopHabitsCSV,
+				"001 Read/Checkmarks.csv": "ignored",
+				"Checkmarks.csv":          loopCheckmarksCSV,
+			}),
+			want: []importedHabit{
+				{
+					habit: domain.Habit{Name: "Read", Goal: "Did you read?", FreezeDays: []string{"2026-10-02"},
+						Schedule: domain.Schedule{Type: domain.ScheduleTimesPerWeek, Times: 3}},
+					checkins: []ExportCheckin{{Date: "2026-10-03", Amount: 1}},
+				},
+				{
+					habit: domain.Habit{Name: "Water", Goal: "How much water?", Unit: "ml", Target: 2000,
+						Schedule: domain.Schedule{Type: domain.ScheduleDaily}},
+					checkins: []ExportCheckin{{Date: "2026-10-03", Amount: 1.5}, {Date: "2026-10-02", Amount: 0.5}},
+				},
+				{
+					habit: domain.Habit{Name: "Old", Goal: "Retired habit", FreezeDays: []string{"2026-10-03"},
+						Schedule: domain.Schedule{Type: domain.ScheduleEveryNDays, Interval: 2}},
+				},
+			},
+		},
+		{
+			name: "single Checkmarks.csv",
+			data: []byte(loopCheckmarksCSV),
+			want: []importedHabit{
+				{
+					habit:    domain.Habit{Name: "Read", FreezeDays: []string{"2026-10-02"}, Schedule: domain.Schedule{Type: domain.ScheduleDaily}},
+					checkins: []ExportCheckin{{Date: "2026-10-03", Amount: 1}},
+				},
+				{
+					// без Habits.csv привычка считается «да/нет»: 1500 — не отметка
+					habit: domain.Habit{Name: "Water", Schedule: domain.Schedule{Type: domain.ScheduleDaily}},
+				},
+				{
+					habit: domain.Habit{Name: "Old", FreezeDays: []string{"2026-10-03"}, Schedule: domain.Schedule{Type: domain.ScheduleDaily}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLoop(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d habits, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				// дату архивации Loop не выгружает — проверяем только наличие
+				archived := got[i].habit.ArchivedAt != nil
+				if want := got[i].habit.Name == "Old" && tt.name == "archive"; archived != want {
+					t.Errorf("%s: archived = %v, want %v", got[i].habit.Name, archived, want)
+				}
+				got[i].habit.ArchivedAt = nil
+				if !reflect.DeepEqual(got[i], tt.want[i]) {
+					t.Errorf("habit %d:\n got %+v\nwant %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseLoopErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"archive without Checkmarks.csv", loopZip(t, map[string]string{"Habits.csv": loopHabitsCSV}), "Checkmarks.csv not found in archive"},
+		{"Habits.csv without Name", loopZip(t, map[string]string{"Habits.csv": "Position,Type\n001,0\n", "Checkmarks.csv": loopCheckmarksCSV}), "Habits.csv: no Name column"},
+		{"empty Habits.csv", loopZip(t, map[string]string{"Habits.csv": "", "Checkmarks.csv": loopCheckmarksCSV}), "Habits.csv: empty file"},
+		{"no Date column", []byte("Name,Read\n"), "Checkmarks.csv: expected Date column first"},
+	}
+	for _, tt := range tests {
+		if _, err := parseLoop(tt.data); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+	"habit-tracker-api/internal/repository/memory"
+)
+
+type importStores struct {
+	habits   *memory.HabitStore
+	checkins *memory.CheckinStore
+	svc      *ImportService
+}
+
+func newImportStores() importStores {
+	checkins := memory.NewCheckinStore()
+	habits := memory.NewHabitStore(checkins)
+	return importStores{habits, checkins, NewImportService(habits, checkins, memory.NewUserStore())}
+}
+
+func exportJSON(t *testing.T, habits ...ExportHabit) []byte {
+	t.Helper()
+	data, err := json.Marshal(ExportData{Version: ExportVersion, Habits: habits})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// Привычки из файла проходят те же проверки, что и созданные через API
+func TestImportValidatesHabits(t *testing.T) {
+	tests := []struct {
+		name     string
+		habit    domain.Habit
+		conflict string
+	}{
+		{"valid", domain.Habit{Name: "Read"}, ""},
+		{"empty name", domain.Habit{}, "habit name is required"},
+		{"unknown schedule", domain.Habit{Name: "Run", Schedule: domain.Schedule{Type: "hourly"}}, "unknown schedule type"},
+		{"weekly without times", domain.Habit{Name: "Swim", Schedule: domain.Schedule{Type: domain.ScheduleTimesPerWeek}}, "schedule times must be between 1 and 7"},
+		{"negative target", domain.Habit{Name: "Walk", Target: -1}, "habit target must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newImportStores()
+			res, err := s.svc.Import("a@example.com", exportJSON(t, ExportHabit{Habit: tt.habit}), ImportFormatJSON, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := res.Habits[0].Conflict; got != tt.conflict {
+				t.Errorf("Conflict = %q, want %q", got, tt.conflict)
+			}
+		})
+	}
+}
+
+// Даты из будущего сдвигаются на сейчас, и по привычке строится отчёт
+func TestImportClampsFutureDates(t *testing.T) {
+	s := newImportStores()
+	future := time.Now().AddDate(0, 1, 0)
+	data := exportJSON(t, ExportHabit{Habit: domain.Habit{Name: "Read", CreatedAt: future, ArchivedAt: &future}})
+	before := time.Now()
+	res, err := s.svc.Import("a@example.com", data, ImportFormatJSON, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := s.habits.FindByID(res.Habits[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.CreatedAt.Before(before) || h.CreatedAt.After(time.Now()) {
+		t.Errorf("CreatedAt = %v, want now", h.CreatedAt)
+	}
+	if h.ArchivedAt == nil || h.ArchivedAt.After(time.Now()) {
+		t.Errorf("ArchivedAt = %v, want now", h.ArchivedAt)
+	}
+	if _, err := buildReport(h, nil, time.UTC, time.Now(), "", ""); err != nil {
+		t.Errorf("report: %v", err)
+	}
+}
+
+// Ошибки разбора файла — ValidationError (400), а не сбой сервера
+func TestImportParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{"unknown format", "{}", "xml"},
+		{"broken JSON", "{", ImportFormatJSON},
+		{"unsupported version", `{"version": 99}`, ImportFormatJSON},
+		{"empty Loop file", "", ImportFormatLoop},
+		{"Loop file without Date column", "Name,Read\n", ImportFormatLoop},
+	}
+	for _, tt := range tests {
+		_, err := newImportStores().svc.Import("a@example.com", []byte(tt.data), tt.format, true)
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("%s: err = %v, want ValidationError", tt.name, err)
+		}
+	}
+}
+
+// Dry-run находит те же конфликты, что и настоящий импорт, но ничего не пишет
+func TestImportDryRunConflicts(t *testing.T) {
+	s := newImportStores()
+	deleted := time.Now()
+	for _, h := range []*domain.Habit{
+		{UserEmail: "a@example.com", Name: "Read"},
+		{UserEmail: "a@example.com", Name: "Run", DeletedAt: &deleted}, // имя из корзины свободно
+		{UserEmail: "b@example.com", Name: "Swim"},                     // чужая привычка не мешает
+	} {
+		if err := s.habits.Create(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := []byte("Date,read,Run,Swim,Swim,\n2026-10-01,2,2,2,2,\n")
+
+	want := []struct{ status, conflict string }{
+		{"conflict", "habit with this name already exists"},
+		{"create", ""},
+		{"create", ""},
+		{"conflict", "habit with this name already exists"}, // повтор внутри файла
+	}
+	res, err := s.svc.Import("a@example.com", data, ImportFormatLoop, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Habits) != len(want) {
+		t.Fatalf("got %d habits, want %d", len(res.Habits), len(want))
+	}
+	for i, w := range want {
+		if r := res.Habits[i]; r.Status != w.status || r.Conflict != w.conflict || r.ID != "" {
+			t.Errorf("habit %d (%s): %s %q, id %q, want %s %q", i, r.Name, r.Status, r.Conflict, r.ID, w.status, w.conflict)
+		}
+	}
+	if res.CreatedHabits != 2 || res.CreatedCheckins != 2 || res.Conflicts != 2 {
+		t.Errorf("result = %+v", res)
+	}
+	if got, _ := s.habits.FindAllByUser("a@example.com"); len(got) != 2 {
+		t.Errorf("dry-run wrote habits: have %d, want 2", len(got))
+	}
+
+	// настоящий импорт того же файла даёт те же итоги
+	real, err := s.svc.Import("a@example.com", data, ImportFormatLoop, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if real.CreatedHabits != res.CreatedHabits || real.Conflicts != res.Conflicts || real.CreatedCheckins != res.CreatedCheckins {
+		t.Errorf("import = %+v, dry-run = %+v", real, res)
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+
+	"habit-tracker-api/internal/domain"
+)
+
+var (
+	// ErrHabitNotFound — привычки с таким ID нет
+	ErrHabitNotFound = domain.ErrHabitNotFound
+	// ErrForbidden — привычка принадлежит другому пользователю
+	ErrForbidden = errors.New("habit belongs to another user")
+	// ErrCheckinNotFound — отметки за этот день нет
+	ErrCheckinNotFound = domain.ErrCheckinNotFound
+	// ErrHabitArchived — привычка в архиве, отмечать её нельзя
+	ErrHabitArchived = errors.New("habit is archived")
+)
+
+// ownedHabit загружает привычку и проверяет, что она принадлежит userEmail.
+// Привычки из корзины для всех операций, кроме восстановления, не существуют.
+func ownedHabit(repo HabitStore, userEmail, id string) (*domain.Habit, error) {
+	h, err := ownedHabitWithTrash(repo, userEmail, id)
+	if err != nil {
+		return nil, err
+	}
+	if h.DeletedAt != nil {
+		return nil, ErrHabitNotFound
+	}
+	return h, nil
+}
+
+// activeHabit — как ownedHabit, но привычка ещё и не в архиве (для отметок)
+func activeHabit(repo HabitStore, userEmail, id string) (*domain.Habit, error) {
+	h, err := ownedHabit(repo, userEmail, id)
+	if err != nil {
+		return nil, err
+	}
+	if h.ArchivedAt != nil {
+		return nil, ErrHabitArchived
+	}
+	return h, nil
+}
+
+// ownedHabitWithTrash — как ownedHabit, но находит и привычки из корзины
+func ownedHabitWithTrash(repo HabitStore, userEmail, id string) (*domain.Habit, error) {
+	// ошибки чтения, кроме «не найдено», отдаются как есть (500, а не 404)
+	h, err := repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if h.UserEmail != userEmail {
+		return nil, ErrForbidden
+	}
+	return h, nil
+}
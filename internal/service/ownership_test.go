@@ -0,0 +1,66 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+	"habit-tracker-api/internal/repository/memory"
+)
+
+// brokenHabitStore — хранилище, у которого не работает чтение
+type brokenHabitStore struct {
+	HabitStore
+	err error
+}
+
+func (s brokenHabitStore) FindByID(string) (*domain.Habit, error) {
+	return nil, s.err
+}
+
+func TestOwnership(t *testing.T) {
+	habits := memory.NewHabitStore(memory.NewCheckinStore())
+	now := time.Now()
+	active := &domain.Habit{UserEmail: "a@example.com", Name: "active"}
+	archived := &domain.Habit{UserEmail: "a@example.com", Name: "archived", ArchivedAt: &now}
+	trashed := &domain.Habit{UserEmail: "a@example.com", Name: "trashed", DeletedAt: &now}
+	for _, h := range []*domain.Habit{active, archived, trashed} {
+		if err := habits.Create(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ioErr := errors.New("disk I/O error")
+
+	type check func(HabitStore, string, string) (*domain.Habit, error)
+	tests := []struct {
+		name  string
+		fn    check
+		store HabitStore
+		user  string
+		id    string
+		want  error
+	}{
+		{"owned", ownedHabit, habits, "a@example.com", active.ID, nil},
+		{"missing", ownedHabit, habits, "a@example.com", "nope", ErrHabitNotFound},
+		{"another user", ownedHabit, habits, "b@example.com", active.ID, ErrForbidden},
+		{"trashed is not found", ownedHabit, habits, "a@example.com", trashed.ID, ErrHabitNotFound},
+		{"trashed of another user", ownedHabit, habits, "b@example.com", trashed.ID, ErrForbidden},
+		{"trashed with trash", ownedHabitWithTrash, habits, "a@example.com", trashed.ID, nil},
+		{"archived is owned", ownedHabit, habits, "a@example.com", archived.ID, nil},
+		{"archived is not active", activeHabit, habits, "a@example.com", archived.ID, ErrHabitArchived},
+		{"active", activeHabit, habits, "a@example.com", active.ID, nil},
+		{"store failure passes through", ownedHabit, brokenHabitStore{err: ioErr}, "a@example.com", active.ID, ioErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := tt.fn(tt.store, tt.user, tt.id)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && h.ID != tt.id {
+				t.Errorf("got habit %s, want %s", h.ID, tt.id)
+			}
+		})
+	}
+}
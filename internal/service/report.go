@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// periodResult — итог одного периода расписания в отчёте
+type periodResult struct {
+	period
+	count     int  // засчитанных отметок (не больше required)
+	possible  int  // сколько отметок требовалось
+	satisfied bool // период выполнен
+	open      bool // период ещё не закончился и не выполнен — не считается пропуском
+	frozen    bool // период целиком пришёлся на дни отдыха
+}
+
+// StreakRun — серия выполненных подряд периодов расписания
+type StreakRun struct {
+	Start  string `json:"start"` // YYYY-MM-DD, начало первого периода
+	End    string `json:"end"`   // YYYY-MM-DD, конец последнего (не позже конца отчёта)
+	Length int    `json:"length"`
+
+	current bool // серия не прервана к концу отчёта
+}
+
+// streakRuns находит все серии выполненных периодов; незакрытый
+// невыполненный период в конце и дни отдыха серию не прерывают
+func streakRuns(results []periodResult, end time.Time) []StreakRun {
+	runs := []StreakRun{}
+	var run *StreakRun
+	for _, r := range results {
+		switch {
+		case r.satisfied:
+			to := r.to
+			if to.After(end) {
+				to = end
+			}
+			if run == nil {
+				runs = append(runs, StreakRun{Start: r.from.Format("2006-01-02")})
+				run = &runs[len(runs)-1]
+			}
+			run.End = to.Format("2006-01-02")
+			run.Length++
+		case r.open, r.frozen:
+		default:
+			run = nil
+		}
+	}
+	if run != nil {
+		run.current = true
+	}
+	return runs
+}
+
+// atRisk — серия оборвётся, если не отметиться сегодня: текущий период открыт,
+// а рабочих дней до его конца (с сегодняшним) не больше, чем недостающих отметок.
+// Для ежедневных привычек это любой ещё не отмеченный день.
+func atRisk(r periodResult, today time.Time, done, frozen map[string]bool) bool {
+	key := today.Format("2006-01-02")
+	if !r.open || done[key] || frozen[key] {
+		return false
+	}
+	left := 0
+	for d := today; !d.After(r.to); d = d.AddDate(0, 0, 1) {
+		if !frozen[d.Format("2006-01-02")] {
+			left++
+		}
+	}
+	return r.required-r.count >= left
+}
+
+// PeriodRate — выполнение за календарную неделю или месяц
+type PeriodRate struct {
+	Period         string  `json:"period"` // 2026-W42 (ISO-неделя) или 2026-10
+	Checks         int     `json:"checks"`
+	Possible       int     `json:"possible"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// periodRates раскладывает периоды расписания по неделям и месяцам
+// (по дню начала периода) и считает процент выполнения каждого
+func periodRates(results []periodResult) (weekly, monthly []PeriodRate) {
+	add := func(rates []PeriodRate, key string, r periodResult) []PeriodRate {
+		if n := len(rates); n == 0 || rates[n-1].Period != key {
+			rates = append(rates, PeriodRate{Period: key})
+		}
+		last := &rates[len(rates)-1]
+		last.Checks += r.count
+		last.Possible += r.possible
+		return rates
+	}
+	weekly, monthly = []PeriodRate{}, []PeriodRate{}
+	for _, r := range results {
+		y, w := r.from.ISOWeek()
+		weekly = add(weekly, fmt.Sprintf("%d-W%02d", y, w), r)
+		monthly = add(monthly, r.from.Format("2006-01"), r)
+	}
+	for _, rates := range [][]PeriodRate{weekly, monthly} {
+		for i := range rates {
+			if rates[i].Possible > 0 {
+				rates[i].CompletionRate = float64(rates[i].Checks) / float64(rates[i].Possible) * 100
+			}
+		}
+	}
+	return weekly, monthly
+}
+
+// bestWeek — неделя с лучшим процентом выполнения (при равенстве — с большим
+// числом отметок, затем более ранняя); nil, если требовать было нечего
+func bestWeek(weekly []PeriodRate) *PeriodRate {
+	var best *PeriodRate
+	for i := range weekly {
+		w := &weekly[i]
+		if w.Possible == 0 {
+			continue
+		}
+		if best == nil || w.CompletionRate > best.CompletionRate ||
+			w.CompletionRate == best.CompletionRate && w.Checks > best.Checks {
+			best = w
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	res := *best
+	return &res
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// reportNow — фиксированный «сейчас» для отчётов: четверг, 15 октября 2026
+var reportNow = time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+// day — дата относительно today (в днях) в формате YYYY-MM-DD
+func day(today time.Time, offset int) string {
+	return today.AddDate(0, 0, offset).Format("2006-01-02")
+}
+
+func TestReport(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		loc      *time.Location // пояс пользователя, по умолчанию UTC
+		now      time.Time      // момент отчёта, по умолчанию reportNow
+		schedule domain.Schedule
+		created  int   // день создания относительно today
+		checks   []int // дни с отметками
+		freeze   []int // дни отдыха
+		archived *int  // день архивации
+		from, to *int  // диапазон отчёта
+		wantTo   *int  // конец отчёта, если он не совпадает с to
+
+		streak, longest, total, possible int
+		runs                             int
+		atRisk                           bool
+	}{
+		{
+			name:    "daily, today still open",
+			created: -5, checks: []int{-5, -4, -3, -2, -1},
+			streak: 5, longest: 5, total: 5, possible: 5, runs: 1, atRisk: true,
+		},
+		{
+			name:    "daily, today done",
+			created: -5, checks: []int{-5, -4, -3, -2, -1, 0},
+			streak: 6, longest: 6, total: 6, possible: 6, runs: 1,
+		},
+		{
+			name:    "daily, missed day breaks streak",
+			created: -5, checks: []int{-5, -4, -2, -1},
+			streak: 2, longest: 2, total: 4, possible: 5, runs: 2, atRisk: true,
+		},
+		{
+			name:    "daily, freeze day keeps streak",
+			created: -5, checks: []int{-5, -4, -2, -1}, freeze: []int{-3},
+			streak: 4, longest: 4, total: 4, possible: 4, runs: 1, atRisk: true,
+		},
+		{
+			name:    "daily, frozen today is not at risk",
+			created: -3, checks: []int{-3, -2, -1}, freeze: []int{0},
+			streak: 3, longest: 3, total: 3, possible: 3, runs: 1,
+		},
+		{
+			// сегодня четверг: до воскресенья ещё четыре дня
+			name:     "weekly, current week open",
+			schedule: domain.Schedule{Type: domain.ScheduleTimesPerWeek, Times: 2},
+			created:  -17, checks: []int{-17, -16, -10, -9},
+			streak: 2, longest: 2, total: 4, possible: 4, runs: 1,
+		},
+		{
+			name:     "weekly, short week missed",
+			schedule: domain.Schedule{Type: domain.ScheduleTimesPerWeek, Times: 2},
+			created:  -17, checks: []int{-10, -9},
+			streak: 1, longest: 1, total: 2, possible: 4, runs: 1,
+		},
+		{
+			name:     "weekly, too few days left",
+			now:      reportNow.AddDate(0, 0, 2),
+			schedule: domain.Schedule{Type: domain.ScheduleTimesPerWeek, Times: 3},
+			created:  -12, checks: []int{-12, -11, -10, -2},
+			streak: 1, longest: 1, total: 4, possible: 4, runs: 1, atRisk: true,
+		},
+		{
+			name:    "range with from and to",
+			created: -9, checks: []int{-9, -8, -7, -6, -5, -4, -3, -2, -1},
+			from: ptr(-5), to: ptr(-3),
+			streak: 3, longest: 3, total: 3, possible: 3, runs: 1,
+		},
+		{
+			name:    "range ends after a miss",
+			created: -9, checks: []int{-9, -8, -7, -5},
+			from: ptr(-8), to: ptr(-6),
+			streak: 0, longest: 2, total: 2, possible: 3, runs: 1,
+		},
+		{
+			// 29 марта в Берлине часы переводят вперёд: в марте на час
+			// меньше, но дней всё равно 31, из них один — день отдыха
+			name:     "monthly, DST month with a freeze day",
+			loc:      berlin,
+			now:      time.Date(2026, 3, 31, 20, 0, 0, 0, berlin),
+			schedule: domain.Schedule{Type: domain.ScheduleTimesPerMonth, Times: 31},
+			created:  -30, checks: span(-30, 0, -21), freeze: []int{-21},
+			streak: 1, longest: 1, total: 30, possible: 30, runs: 1,
+		},
+		{
+			name:    "daily, DST week",
+			loc:     berlin,
+			now:     time.Date(2026, 3, 30, 8, 0, 0, 0, berlin),
+			created: -7, checks: []int{-7, -6, -5, -4, -3, -2, -1},
+			streak: 7, longest: 7, total: 7, possible: 7, runs: 1, atRisk: true,
+		},
+		{
+			name:    "archived habit stops at archive day",
+			created: -10, checks: []int{-10, -9, -8, -7, -6, -3}, archived: ptr(-6),
+			wantTo: ptr(-6),
+			streak: 5, longest: 5, total: 5, possible: 5, runs: 1,
+		},
+		{
+			name:    "archived habit with a miss",
+			created: -10, checks: []int{-10, -9, -7, -6}, archived: ptr(-6),
+			wantTo: ptr(-6),
+			streak: 2, longest: 2, total: 4, possible: 5, runs: 2,
+		},
+		{
+			name:    "archived habit, range past archive day",
+			created: -10, checks: []int{-10, -9, -8, -7, -6}, archived: ptr(-6),
+			from: ptr(-8), to: ptr(-1), wantTo: ptr(-6),
+			streak: 3, longest: 3, total: 3, possible: 3, runs: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, now := tt.loc, tt.now
+			if loc == nil {
+				loc = time.UTC
+			}
+			if now.IsZero() {
+				now = reportNow
+			}
+			today := startOfDay(now, loc)
+
+			h := &domain.Habit{
+				ID:        "h",
+				UserEmail: "a@example.com",
+				Name:      "habit",
+				Schedule:  tt.schedule,
+				CreatedAt: today.AddDate(0, 0, tt.created).Add(9 * time.Hour),
+			}
+			if h.Schedule.Type == "" {
+				h.Schedule.Type = domain.ScheduleDaily
+			}
+			for _, d := range tt.freeze {
+				h.FreezeDays = append(h.FreezeDays, day(today, d))
+			}
+			if tt.archived != nil {
+				at := today.AddDate(0, 0, *tt.archived).Add(20 * time.Hour)
+				h.ArchivedAt = &at
+			}
+			var checks []domain.HabitCheckin
+			for _, d := range tt.checks {
+				checks = append(checks, domain.HabitCheckin{HabitID: h.ID, Date: today.AddDate(0, 0, d), Amount: 1})
+			}
+
+			var from, to string
+			if tt.from != nil {
+				from = day(today, *tt.from)
+			}
+			if tt.to != nil {
+				to = day(today, *tt.to)
+			}
+			r, err := buildReport(h, checks, loc, now, from, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Streak != tt.streak {
+				t.Errorf("Streak = %d, want %d", r.Streak, tt.streak)
+			}
+			if r.LongestStreak != tt.longest {
+				t.Errorf("LongestStreak = %d, want %d", r.LongestStreak, tt.longest)
+			}
+			if r.TotalChecks != tt.total || r.PossibleChecks != tt.possible {
+				t.Errorf("checks = %d/%d, want %d/%d", r.TotalChecks, r.PossibleChecks, tt.total, tt.possible)
+			}
+			if len(r.Streaks) != tt.runs {
+				t.Errorf("Streaks = %+v, want %d runs", r.Streaks, tt.runs)
+			}
+			if r.AtRisk != tt.atRisk {
+				t.Errorf("AtRisk = %v, want %v", r.AtRisk, tt.atRisk)
+			}
+			if from != "" && r.From != from {
+				t.Errorf("From = %s, want %s", r.From, from)
+			}
+			wantTo := to
+			if tt.wantTo != nil {
+				wantTo = day(today, *tt.wantTo)
+			}
+			if wantTo != "" && r.To != wantTo {
+				t.Errorf("To = %s, want %s", r.To, wantTo)
+			}
+		})
+	}
+}
+
+// Ошибки диапазона
+func TestReportInvalidRange(t *testing.T) {
+	today := startOfDay(reportNow, time.UTC)
+	archivedAt := today.AddDate(0, 0, -3)
+	active := &domain.Habit{
+		ID: "h", Name: "habit",
+		Schedule:  domain.Schedule{Type: domain.ScheduleDaily},
+		CreatedAt: today.AddDate(0, 0, -10),
+	}
+	archived := *active
+	archived.ArchivedAt = &archivedAt
+
+	tests := []struct {
+		name     string
+		h        *domain.Habit
+		from, to string
+	}{
+		{"bad from", active, "bad", ""},
+		{"bad to", active, "", "bad"},
+		{"from after to", active, day(today, 0), day(today, -1)},
+		{"from after archive day", &archived, day(today, -1), ""},
+	}
+	for _, tt := range tests {
+		if _, err := buildReport(tt.h, nil, time.UTC, reportNow, tt.from, tt.to); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func ptr(v int) *int {
+	return &v
+}
+
+// span — дни от from до to включительно, кроме skip
+func span(from, to int, skip ...int) []int {
+	var days []int
+	for d := from; d <= to; d++ {
+		if !slices.Contains(skip, d) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
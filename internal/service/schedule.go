@@ -0,0 +1,108 @@
+package service
+
+import (
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// validateSchedule проверяет расписание и приводит пустое к ежедневному
+func validateSchedule(s *domain.Schedule) error {
+	switch s.Type {
+	case "", domain.ScheduleDaily:
+		s.Type = domain.ScheduleDaily
+	case domain.ScheduleWeekdays:
+		if len(s.Weekdays) == 0 {
+			return invalidInput("schedule weekdays are required")
+		}
+		for _, d := range s.Weekdays {
+			if d < time.Sunday || d > time.Saturday {
+				return invalidInput("invalid schedule weekday")
+			}
+		}
+	case domain.ScheduleTimesPerWeek:
+		if s.Times < 1 || s.Times > 7 {
+			return invalidInput("schedule times must be between 1 and 7")
+		}
+	case domain.ScheduleTimesPerMonth:
+		if s.Times < 1 || s.Times > 31 {
+			return invalidInput("schedule times must be between 1 and 31")
+		}
+	case domain.ScheduleEveryNDays:
+		if s.Interval < 1 {
+			return invalidInput("schedule interval must be positive")
+		}
+	default:
+		return invalidInput("unknown schedule type")
+	}
+	return nil
+}
+
+// period — отрезок календаря [from, to], за который привычку
+// нужно выполнить required раз
+type period struct {
+	from, to time.Time
+	required int
+}
+
+// schedulePeriods нарезает дни от start до today на периоды расписания.
+// Обе даты должны быть полуночью одного и того же дня-формата.
+func schedulePeriods(s domain.Schedule, start, today time.Time) []period {
+	var res []period
+	switch s.Type {
+	case domain.ScheduleWeekdays:
+		days := make(map[time.Weekday]bool, len(s.Weekdays))
+		for _, d := range s.Weekdays {
+			days[d] = true
+		}
+		for d := start; !d.After(today); d = d.AddDate(0, 0, 1) {
+			if days[d.Weekday()] {
+				res = append(res, period{d, d, 1})
+			}
+		}
+	case domain.ScheduleTimesPerWeek:
+		// недели начинаются с понедельника
+		offset := (int(start.Weekday()) + 6) % 7
+		for w := start.AddDate(0, 0, -offset); !w.After(today); w = w.AddDate(0, 0, 7) {
+			res = append(res, clipPeriod(w, w.AddDate(0, 0, 6), start, s.Times))
+		}
+	case domain.ScheduleTimesPerMonth:
+		m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+		for ; !m.After(today); m = m.AddDate(0, 1, 0) {
+			res = append(res, clipPeriod(m, m.AddDate(0, 1, -1), start, s.Times))
+		}
+	case domain.ScheduleEveryNDays:
+		for d := start; !d.After(today); d = d.AddDate(0, 0, s.Interval) {
+			res = append(res, period{d, d.AddDate(0, 0, s.Interval-1), 1})
+		}
+	default:
+		for d := start; !d.After(today); d = d.AddDate(0, 0, 1) {
+			res = append(res, period{d, d, 1})
+		}
+	}
+	return res
+}
+
+// clipPeriod обрезает первый период по дате создания привычки:
+// в неполной неделе/месяце нельзя требовать больше отметок, чем осталось дней
+func clipPeriod(from, to, start time.Time, times int) period {
+	if from.Before(start) {
+		from = start
+	}
+	days := calendarDays(from, to)
+	if times > days {
+		times = days
+	}
+	return period{from, to, times}
+}
+
+// calendarDays — число календарных дней в отрезке [from, to] включительно.
+// Считает по датам, а не по часам: сутки перехода на летнее/зимнее время
+// длятся 23 или 25 часов
+func calendarDays(from, to time.Time) int {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	a := time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)
+	b := time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a)/(24*time.Hour)) + 1
+}
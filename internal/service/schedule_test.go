@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"habit-tracker-api/internal/domain"
+)
+
+func TestCalendarDays(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(m time.Month, d int) time.Time {
+		return time.Date(2026, m, d, 0, 0, 0, 0, berlin)
+	}
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{"single day", date(time.March, 10), date(time.March, 10), 1},
+		{"plain week", date(time.March, 16), date(time.March, 22), 7},
+		// 29 марта часы переводят вперёд: эти сутки длятся 23 часа
+		{"spring forward day and next", date(time.March, 29), date(time.March, 30), 2},
+		{"month with spring forward", date(time.March, 1), date(time.March, 31), 31},
+		// 25 октября часы переводят назад: эти сутки длятся 25 часов
+		{"fall back day and next", date(time.October, 25), date(time.October, 26), 2},
+		{"month with fall back", date(time.October, 1), date(time.October, 31), 31},
+	}
+	for _, tt := range tests {
+		if got := calendarDays(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: calendarDays = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Требование месяца с переходом на летнее время не урезается
+func TestSchedulePeriodsDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(d int) time.Time {
+		return time.Date(2026, time.March, d, 0, 0, 0, 0, berlin)
+	}
+	s := domain.Schedule{Type: domain.ScheduleTimesPerMonth, Times: 31}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		want  int
+	}{
+		{"full month", date(1), 31},
+		{"created mid-month", date(15), 17},
+		{"created on the last day", date(31), 1},
+	}
+	for _, tt := range tests {
+		periods := schedulePeriods(s, tt.start, date(31))
+		if len(periods) != 1 {
+			t.Fatalf("%s: got %d periods, want 1", tt.name, len(periods))
+		}
+		if got := periods[0].required; got != tt.want {
+			t.Errorf("%s: required = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
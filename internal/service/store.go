@@ -0,0 +1,73 @@
+package service
+
+import (
+	"io"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+)
+
+// HabitStore — хранилище привычек
+type HabitStore interface {
+	Create(h *domain.Habit) error
+	FindAllByUser(email string) ([]*domain.Habit, error)
+	FindByID(id string) (*domain.Habit, error)
+	Update(h *domain.Habit) error
+	Delete(id string) error // вместе с отметками привычки
+	// ForEach обходит все привычки по возрастанию ID
+	ForEach(fn func(h *domain.Habit) error) error
+}
+
+// CheckinStore — хранилище отметок; одна запись на привычку и день (YYYY-MM-DD).
+// Create суммирует количество с уже существующей отметкой за тот же день.
+type CheckinStore interface {
+	Create(hc *domain.HabitCheckin) error
+	// CreateMany — как Create для каждой отметки, но одной транзакцией (импорт истории)
+	CreateMany(hcs []domain.HabitCheckin) error
+	FindByHabit(habitID string) ([]domain.HabitCheckin, error)
+	// FindByHabits — отметки нескольких привычек за одно обращение, по ID привычки
+	FindByHabits(habitIDs []string) (map[string][]domain.HabitCheckin, error)
+	FindByHabitAndDate(habitID, day string) (*domain.HabitCheckin, error)
+	Update(hc *domain.HabitCheckin) error
+	Delete(habitID, day string) error
+	// ForEach обходит все отметки по возрастанию (habitID, день)
+	ForEach(fn func(hc *domain.HabitCheckin) error) error
+}
+
+// UserStore — хранилище пользователей
+type UserStore interface {
+	Create(user *domain.User) error
+	FindByEmail(email string) (*domain.User, error)
+	// FindByCalendarToken ищет пользователя по хэшу токена ленты календаря
+	FindByCalendarToken(hash string) (*domain.User, error)
+	// FindByHeatmapToken ищет пользователя по хэшу токена публичной тепловой карты
+	FindByHeatmapToken(hash string) (*domain.User, error)
+	Update(user *domain.User) error
+	// ForEach обходит всех пользователей по возрастанию email
+	ForEach(fn func(u *domain.User) error) error
+}
+
+// TokenStore — хранилище refresh-токенов, отзывов и одноразовых токенов из писем
+type TokenStore interface {
+	SaveRefreshToken(rt *domain.RefreshToken) error
+	ConsumeRefreshToken(hash string) (*domain.RefreshToken, error)
+	DeleteRefreshToken(email, hash string) error
+	RevokeAll(email string, since time.Time) error
+	RevokedBefore(email string) (time.Time, error)
+	RevokeAccessToken(tokenID string, expiresAt time.Time) error
+	IsAccessTokenRevoked(tokenID string) (bool, error)
+	SaveUserToken(ut *domain.UserToken) error
+	ConsumeUserToken(hash, purpose string) (*domain.UserToken, error)
+}
+
+// Stores — набор хранилищ одного бэкенда
+type Stores struct {
+	Habits   HabitStore
+	Checkins CheckinStore
+	Users    UserStore
+	Tokens   TokenStore
+
+	// Snapshot пишет согласованную копию базы на лету;
+	// nil, если бэкенд этого не умеет
+	Snapshot io.WriterTo
+}
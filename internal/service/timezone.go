@@ -0,0 +1,34 @@
+package service
+
+import (
+	"time"
+)
+
+// loadTimezone проверяет IANA-имя часового пояса; пустое имя — UTC
+func loadTimezone(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, invalidInput("invalid timezone")
+	}
+	return loc, nil
+}
+
+// userLocation возвращает часовой пояс пользователя.
+// Если пользователь не найден или пояс не задан — UTC.
+func userLocation(users UserStore, email string) *time.Location {
+	u, err := users.FindByEmail(email)
+	if err != nil {
+		return time.UTC
+	}
+	loc, err := loadTimezone(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// startOfDay возвращает полночь того дня, в который t попадает в поясе loc
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"habit-tracker-api/internal/auth"
+	"habit-tracker-api/internal/domain"
+	"habit-tracker-api/pkg/hash"
+)
+
+const (
+	passwordResetTTL = time.Hour
+	emailVerifyTTL   = 48 * time.Hour
+)
+
+var ErrInvalidUserToken = errors.New("invalid or expired token")
+
+// RequestPasswordReset отправляет письмо со ссылкой для сброса пароля.
+// Для неизвестного email молча ничего не делает, чтобы не раскрывать, кто зарегистрирован.
+func (s *UserService) RequestPasswordReset(email string) error {
+	if _, err := s.repo.FindByEmail(email); err != nil {
+		return nil
+	}
+	token, err := s.issueUserToken(email, domain.TokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf(
+		"To set a new password send this token with the new password to POST %s/password/reset:\n%s\n\n"+
+			"The token is valid for %s. If you did not request a reset, ignore this email.",
+		s.appURL, token, passwordResetTTL)
+	return s.mailer.Send(email, "Password reset", body)
+}
+
+// ResetPassword меняет пароль по одноразовому токену и завершает все сессии
+func (s *UserService) ResetPassword(token, newPassword string) error {
+	ut, err := s.consumeUserToken(token, domain.TokenPasswordReset)
+	if err != nil {
+		return err
+	}
+	user, err := s.repo.FindByEmail(ut.UserEmail)
+	if err != nil {
+		return ErrInvalidUserToken
+	}
+	hashed, err := hash.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	// письмо дошло — значит, адрес рабочий
+	user.Verified = true
+	if err := s.repo.Update(user); err != nil {
+		return err
+	}
+	return s.tokens.RevokeAll(user.Email, time.Now())
+}
+
+// SendVerification отправляет письмо для подтверждения email
+func (s *UserService) SendVerification(email string) error {
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user.Verified {
+		return errors.New("email already verified")
+	}
+	token, err := s.issueUserToken(email, domain.TokenEmailVerify, emailVerifyTTL)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf(
+		"Confirm your email by opening %s/email/verify?token=%s\n"+
+			"or send this token to POST /email/verify: %s\n\n"+
+			"The link is valid for %s.",
+		s.appURL, token, token, emailVerifyTTL)
+	return s.mailer.Send(email, "Confirm your email", body)
+}
+
+// VerifyEmail помечает email подтверждённым по одноразовому токену
+func (s *UserService) VerifyEmail(token string) error {
+	ut, err := s.consumeUserToken(token, domain.TokenEmailVerify)
+	if err != nil {
+		return err
+	}
+	user, err := s.repo.FindByEmail(ut.UserEmail)
+	if err != nil {
+		return ErrInvalidUserToken
+	}
+	user.Verified = true
+	return s.repo.Update(user)
+}
+
+func (s *UserService) issueUserToken(email, purpose string, ttl time.Duration) (string, error) {
+	token, tokenHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = s.tokens.SaveUserToken(&domain.UserToken{
+		Hash:      tokenHash,
+		UserEmail: email,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *UserService) consumeUserToken(token, purpose string) (*domain.UserToken, error) {
+	ut, err := s.tokens.ConsumeUserToken(auth.HashToken(token), purpose)
+	if err != nil {
+		return nil, ErrInvalidUserToken
+	}
+	if time.Now().After(ut.ExpiresAt) {
+		return nil, ErrInvalidUserToken
+	}
+	return ut, nil
+}
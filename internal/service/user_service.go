@@ -4,44 +4,181 @@ import (
 	"errors"
 	"habit-tracker-api/internal/auth"
 	"habit-tracker-api/internal/domain"
-	"habit-tracker-api/internal/repository"
+	"habit-tracker-api/internal/mailer"
 	"habit-tracker-api/pkg/hash"
+	"log"
+	"time"
 )
 
 type UserService struct {
-	repo *repository.UserRepository
+	repo       UserStore
+	tokens     TokenStore
+	jwt        *auth.JWTManager
+	refreshTTL time.Duration
+	mailer     mailer.Mailer
+	appURL     string // базовый адрес для ссылок в письмах
 }
 
-func NewUserService(repo *repository.UserRepository) *UserService {
-	return &UserService{repo}
+func NewUserService(
+	repo UserStore,
+	tokens TokenStore,
+	jwt *auth.JWTManager,
+	refreshTTL time.Duration,
+	m mailer.Mailer,
+	appURL string,
+) *UserService {
+	return &UserService{repo, tokens, jwt, refreshTTL, m, appURL}
 }
 
-func (s *UserService) Register(email, password string) error {
+// Register создаёт пользователя; timezone — IANA-имя, пустое означает UTC
+func (s *UserService) Register(email, password, timezone string) error {
+	if _, err := loadTimezone(timezone); err != nil {
+		return err
+	}
 	hashed, err := hash.HashPassword(password)
 	if err != nil {
 		return err
 	}
 	user := &domain.User{
-		Email:    email,
-		Password: hashed,
+		Email:     email,
+		Password:  hashed,
+		Timezone:  timezone,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(user); err != nil {
+		return err
+	}
+	// ошибка почты не отменяет регистрацию: письмо можно запросить повторно
+	if err := s.SendVerification(email); err != nil {
+		log.Printf("failed to send verification email to %s: %v", email, err)
+	}
+	return nil
+}
+
+// GetByEmail возвращает профиль пользователя
+func (s *UserService) GetByEmail(email string) (*domain.User, error) {
+	return s.repo.FindByEmail(email)
+}
+
+// IsVerified реализует auth.VerifiedChecker
+func (s *UserService) IsVerified(email string) (bool, error) {
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return false, err
 	}
-	return s.repo.Create(user)
+	return user.Verified, nil
 }
 
-var ErrInvalidCredentials = errors.New("invalid email or password")
+// UpdateTimezone меняет часовой пояс, в котором считаются дни пользователя
+func (s *UserService) UpdateTimezone(email, timezone string) error {
+	if _, err := loadTimezone(timezone); err != nil {
+		return err
+	}
+	user, err := s.repo.FindByEmail(email)
+	if err != nil {
+		return err
+	}
+	user.Timezone = timezone
+	return s.repo.Update(user)
+}
+
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+)
 
-func (s *UserService) Login(email, password string) (string, error) {
+// TokenPair — короткоживущий access-токен и refresh-токен для его обновления
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // секунд до истечения access-токена
+}
+
+func (s *UserService) Login(email, password string) (*TokenPair, error) {
 	user, err := s.repo.FindByEmail(email)
 	if err != nil {
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	}
 	if !hash.CheckPasswordHash(password, user.Password) {
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
+	}
+	return s.issueTokens(user.Email)
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов.
+// Старый refresh-токен при этом сгорает (ротация).
+func (s *UserService) Refresh(refreshToken string) (*TokenPair, error) {
+	rt, err := s.tokens.ConsumeRefreshToken(auth.HashToken(refreshToken))
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if time.Now().After(rt.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if _, err := s.repo.FindByEmail(rt.UserEmail); err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	return s.issueTokens(rt.UserEmail)
+}
+
+// Logout отзывает текущий access-токен и, если передан, refresh-токен
+func (s *UserService) Logout(email, tokenID string, expiresAt time.Time, refreshToken string) error {
+	if refreshToken != "" {
+		if err := s.tokens.DeleteRefreshToken(email, auth.HashToken(refreshToken)); err != nil {
+			return err
+		}
+	}
+	if tokenID == "" {
+		return nil
+	}
+	return s.tokens.RevokeAccessToken(tokenID, expiresAt)
+}
+
+// LogoutAll — выход на всех устройствах: все выданные ранее токены недействительны
+func (s *UserService) LogoutAll(email string) error {
+	return s.tokens.RevokeAll(email, time.Now())
+}
+
+// IsRevoked реализует auth.Revoker
+func (s *UserService) IsRevoked(tokenID, email string, issuedAt time.Time) (bool, error) {
+	before, err := s.tokens.RevokedBefore(email)
+	if err != nil {
+		return false, err
+	}
+	// iat в JWT хранится с точностью до секунды: токен, выпущенный в ту же
+	// секунду, что и «выйти везде», тоже считается отозванным
+	if !before.IsZero() && !issuedAt.After(before.Truncate(time.Second)) {
+		return true, nil
+	}
+	if tokenID == "" {
+		return false, nil
+	}
+	return s.tokens.IsAccessTokenRevoked(tokenID)
+}
+
+// issueTokens выпускает access-токен и сохраняет новый refresh-токен
+func (s *UserService) issueTokens(email string) (*TokenPair, error) {
+	access, err := s.jwt.GenerateJWT(email)
+	if err != nil {
+		return nil, err
+	}
+	refresh, refreshHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
 	}
-	// Генерируем JWT
-	token, err := auth.GenerateJWT(user.Email)
+	now := time.Now()
+	err = s.tokens.SaveRefreshToken(&domain.RefreshToken{
+		Hash:      refreshHash,
+		UserEmail: email,
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return token, nil
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(s.jwt.TTL().Seconds()),
+	}, nil
 }
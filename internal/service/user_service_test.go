@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/auth"
+	"habit-tracker-api/internal/mailer"
+	"habit-tracker-api/internal/repository/memory"
+)
+
+func newTestUserService(t *testing.T) (*UserService, *auth.JWTManager) {
+	t.Helper()
+	jwt := auth.NewJWTManager("test-secret", 15*time.Minute)
+	m := mailer.NewLogMailer(filepath.Join(t.TempDir(), "mail.log"))
+	s := NewUserService(memory.NewUserStore(), memory.NewTokenStore(), jwt, time.Hour, m, "http://localhost")
+	if err := s.Register("a@example.com", "secret123", ""); err != nil {
+		t.Fatal(err)
+	}
+	return s, jwt
+}
+
+func TestRefreshRotation(t *testing.T) {
+	s, _ := newTestUserService(t)
+	pair, err := s.Login("a@example.com", "secret123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, err := s.Refresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.RefreshToken == pair.RefreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"used token is rejected", pair.RefreshToken, ErrInvalidRefreshToken},
+		{"unknown token is rejected", "nope", ErrInvalidRefreshToken},
+		{"new token works", next.RefreshToken, nil},
+		{"new token works only once", next.RefreshToken, ErrInvalidRefreshToken},
+	}
+	for _, tt := range tests {
+		if _, err := s.Refresh(tt.token); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLogoutRevokesTokens(t *testing.T) {
+	s, jwt := newTestUserService(t)
+	pair, err := s.Login("a@example.com", "secret123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := jwt.ParseJWT(pair.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := s.IsRevoked(claims.ID, claims.Email, claims.IssuedAt.Time); err != nil || revoked {
+		t.Fatalf("fresh token: revoked = %v, err = %v", revoked, err)
+	}
+	if err := s.Logout(claims.Email, claims.ID, claims.ExpiresAt.Time, pair.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := s.IsRevoked(claims.ID, claims.Email, claims.IssuedAt.Time); err != nil || !revoked {
+		t.Errorf("after logout: revoked = %v, err = %v", revoked, err)
+	}
+	if _, err := s.Refresh(pair.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("refresh after logout: err = %v", err)
+	}
+}
+
+func TestLogoutAll(t *testing.T) {
+	s, _ := newTestUserService(t)
+	if err := s.LogoutAll("a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	before, err := s.tokens.RevokedBefore("a@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// iat хранится с точностью до секунды
+	second := before.Truncate(time.Second)
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{"issued a second earlier", second.Add(-time.Second), true},
+		{"issued in the same second", second, true},
+		{"issued a second later", second.Add(time.Second), false},
+	}
+	for _, tt := range tests {
+		revoked, err := s.IsRevoked("", "a@example.com", tt.issuedAt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revoked != tt.want {
+			t.Errorf("%s: revoked = %v, want %v", tt.name, revoked, tt.want)
+		}
+	}
+	// другого пользователя «выйти везде» не касается
+	if revoked, _ := s.IsRevoked("", "b@example.com", second); revoked {
+		t.Error("other user's token is revoked")
+	}
+}
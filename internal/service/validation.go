@@ -0,0 +1,15 @@
+package service
+
+// ValidationError — неверные данные запроса (пустое имя, неизвестное расписание,
+// неизвестный часовой пояс…); хендлеры отвечают на неё 400
+type ValidationError struct {
+	msg string
+}
+
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+func invalidInput(msg string) error {
+	return &ValidationError{msg}
+}
@@ -0,0 +1,286 @@
+// Package transfer переносит пользователей, привычки и отметки
+// из одного хранилища в другое (например, BoltDB -> SQLite и обратно).
+//
+// Перенос можно прервать и запустить снова: прогресс сохраняется в файле
+// состояния, а запись в приёмник идемпотентна (существующие записи перезаписываются).
+// Токены и сессии не переносятся — после переезда пользователи входят заново.
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+	"habit-tracker-api/internal/service"
+)
+
+// Этапы переноса в порядке выполнения
+const (
+	phaseUsers    = "users"
+	phaseHabits   = "habits"
+	phaseCheckins = "checkins"
+	phaseDone     = "done"
+)
+
+// checkpointEvery — как часто (в записях) сохранять прогресс
+const checkpointEvery = 500
+
+// state — содержимое файла состояния
+type state struct {
+	Phase   string `json:"phase"`
+	LastKey string `json:"last_key"` // ключ последней перенесённой записи текущего этапа
+}
+
+// Counts — количество записей и контрольная сумма по каждому виду данных
+type Counts struct {
+	Users            int    `json:"users"`
+	Habits           int    `json:"habits"`
+	Checkins         int    `json:"checkins"`
+	UsersChecksum    string `json:"users_checksum"`
+	HabitsChecksum   string `json:"habits_checksum"`
+	CheckinsChecksum string `json:"checkins_checksum"`
+}
+
+// Run переносит данные из src в dst, продолжая с места, записанного в statePath.
+// После успешного переноса сверяет количество записей и контрольные суммы.
+func Run(src, dst *service.Stores, statePath string, logf func(format string, args ...any)) (*Counts, error) {
+	st, err := loadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+	if st.Phase != phaseDone {
+		if err := copyAll(src, dst, st, statePath, logf); err != nil {
+			return nil, err
+		}
+	}
+
+	logf("verifying...")
+	srcCounts, err := Summarize(src)
+	if err != nil {
+		return nil, fmt.Errorf("summarize source: %w", err)
+	}
+	dstCounts, err := Summarize(dst)
+	if err != nil {
+		return nil, fmt.Errorf("summarize destination: %w", err)
+	}
+	if *srcCounts != *dstCounts {
+		return srcCounts, fmt.Errorf("verification failed: source %+v, destination %+v", *srcCounts, *dstCounts)
+	}
+	return srcCounts, nil
+}
+
+func copyAll(src, dst *service.Stores, st *state, statePath string, logf func(string, ...any)) error {
+	n := 0
+	// checkpoint запоминает последний ключ и периодически сохраняет состояние
+	checkpoint := func(key string) error {
+		st.LastKey = key
+		n++
+		if n%checkpointEvery == 0 {
+			logf("%s: %d copied", st.Phase, n)
+			return saveState(statePath, st)
+		}
+		return nil
+	}
+	next := func(phase string) error {
+		logf("%s: %d copied", st.Phase, n)
+		st.Phase, st.LastKey, n = phase, "", 0
+		return saveState(statePath, st)
+	}
+
+	if st.Phase == phaseUsers {
+		err := src.Users.ForEach(func(u *domain.User) error {
+			if st.LastKey != "" && u.Email <= st.LastKey {
+				return nil
+			}
+			if err := putUser(dst.Users, u); err != nil {
+				return fmt.Errorf("user %s: %w", u.Email, err)
+			}
+			return checkpoint(u.Email)
+		})
+		if err != nil {
+			saveState(statePath, st)
+			return err
+		}
+		if err := next(phaseHabits); err != nil {
+			return err
+		}
+	}
+
+	if st.Phase == phaseHabits {
+		err := src.Habits.ForEach(func(h *domain.Habit) error {
+			if st.LastKey != "" && h.ID <= st.LastKey {
+				return nil
+			}
+			if err := putHabit(dst.Habits, h); err != nil {
+				return fmt.Errorf("habit %s: %w", h.ID, err)
+			}
+			return checkpoint(h.ID)
+		})
+		if err != nil {
+			saveState(statePath, st)
+			return err
+		}
+		if err := next(phaseCheckins); err != nil {
+			return err
+		}
+	}
+
+	if st.Phase == phaseCheckins {
+		err := src.Checkins.ForEach(func(hc *domain.HabitCheckin) error {
+			key := checkinKey(hc)
+			if st.LastKey != "" && key <= st.LastKey {
+				return nil
+			}
+			if err := putCheckin(dst.Checkins, hc); err != nil {
+				return fmt.Errorf("checkin %s: %w", key, err)
+			}
+			return checkpoint(key)
+		})
+		if err != nil {
+			saveState(statePath, st)
+			return err
+		}
+		if err := next(phaseDone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putUser создаёт пользователя или перезаписывает уже перенесённого
+func putUser(users service.UserStore, u *domain.User) error {
+	if _, err := users.FindByEmail(u.Email); err == nil {
+		return users.Update(u)
+	}
+	return users.Create(u)
+}
+
+// putHabit создаёт привычку или перезаписывает уже перенесённую
+func putHabit(habits service.HabitStore, h *domain.Habit) error {
+	if _, err := habits.FindByID(h.ID); err == nil {
+		return habits.Update(h)
+	}
+	return habits.Create(h)
+}
+
+// putCheckin переносит отметку как есть: Create в хранилищах суммирует
+// количество и выдаёт новый ID, поэтому запись затем перезаписывается целиком
+func putCheckin(checkins service.CheckinStore, hc *domain.HabitCheckin) error {
+	day := hc.Date.Format("2006-01-02")
+	_, err := checkins.FindByHabitAndDate(hc.HabitID, day)
+	switch {
+	case errors.Is(err, domain.ErrCheckinNotFound):
+		created := *hc
+		if err := checkins.Create(&created); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	}
+	return checkins.Update(hc)
+}
+
+func checkinKey(hc *domain.HabitCheckin) string {
+	return hc.HabitID + "|" + hc.Date.Format("2006-01-02")
+}
+
+// Summarize считает записи и контрольные суммы хранилища.
+// Сумма — XOR SHA-256 от JSON каждой записи, поэтому не зависит от порядка обхода.
+// Моменты времени хэшируются в UTC: SQLite хранит их в UTC, а Bolt — с исходным
+// смещением, и это не расхождение. Дата отметки сравнивается как есть.
+func Summarize(s *service.Stores) (*Counts, error) {
+	var (
+		c                       Counts
+		users, habits, checkins digest
+	)
+	err := s.Users.ForEach(func(u *domain.User) error {
+		c.Users++
+		v := *u
+		v.CreatedAt = v.CreatedAt.UTC()
+		return users.add(&v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.Habits.ForEach(func(h *domain.Habit) error {
+		c.Habits++
+		v := *h
+		v.CreatedAt = v.CreatedAt.UTC()
+		v.ArchivedAt, v.DeletedAt = utcPtr(v.ArchivedAt), utcPtr(v.DeletedAt)
+		return habits.add(&v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.Checkins.ForEach(func(hc *domain.HabitCheckin) error {
+		c.Checkins++
+		return checkins.add(hc)
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.UsersChecksum = users.String()
+	c.HabitsChecksum = habits.String()
+	c.CheckinsChecksum = checkins.String()
+	return &c, nil
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// digest — XOR хэшей записей
+type digest [sha256.Size]byte
+
+func (d *digest) add(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	for i := range d {
+		d[i] ^= sum[i]
+	}
+	return nil
+}
+
+func (d *digest) String() string {
+	return hex.EncodeToString(d[:])
+}
+
+func loadState(path string) (*state, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &state{Phase: phaseUsers}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse state file %s: %w", path, err)
+	}
+	return &st, nil
+}
+
+func saveState(path string, st *state) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	// пишем через временный файл, чтобы не оставить обрезанное состояние
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
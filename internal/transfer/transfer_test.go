@@ -0,0 +1,188 @@
+package transfer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"habit-tracker-api/internal/domain"
+	"habit-tracker-api/internal/repository/memory"
+	"habit-tracker-api/internal/repository/sqlite"
+	"habit-tracker-api/internal/service"
+)
+
+func memoryStores() *service.Stores {
+	checkins := memory.NewCheckinStore()
+	return &service.Stores{
+		Habits:   memory.NewHabitStore(checkins),
+		Checkins: checkins,
+		Users:    memory.NewUserStore(),
+		Tokens:   memory.NewTokenStore(),
+	}
+}
+
+func sqliteStores(t *testing.T) *service.Stores {
+	t.Helper()
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "habits.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &service.Stores{
+		Habits:   sqlite.NewHabitRepository(db),
+		Checkins: sqlite.NewHabitCheckinRepository(db),
+		Users:    sqlite.NewUserRepository(db),
+		Tokens:   sqlite.NewTokenRepository(db),
+	}
+}
+
+// seed заполняет хранилище: времена — в поясе с ненулевым смещением
+func seed(t *testing.T, s *service.Stores, habits int) {
+	t.Helper()
+	moscow := time.FixedZone("MSK", 3*60*60)
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, moscow)
+	if err := s.Users.Create(&domain.User{Email: "a@example.com", CreatedAt: created}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < habits; i++ {
+		h := &domain.Habit{
+			ID:        string(rune('a'+i)) + "-habit",
+			UserEmail: "a@example.com",
+			Name:      "habit",
+			Schedule:  domain.Schedule{Type: domain.ScheduleDaily},
+			CreatedAt: created,
+		}
+		if err := s.Habits.Create(h); err != nil {
+			t.Fatal(err)
+		}
+		for d := 0; d < 3; d++ {
+			hc := &domain.HabitCheckin{HabitID: h.ID, Date: created.AddDate(0, 0, d), Amount: float64(d + 1)}
+			if err := s.Checkins.Create(hc); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+// failingHabits — приёмник, который ломается после limit записей
+type failingHabits struct {
+	service.HabitStore
+	limit int
+}
+
+func (f *failingHabits) Create(h *domain.Habit) error {
+	if f.limit == 0 {
+		return errors.New("disk full")
+	}
+	f.limit--
+	return f.HabitStore.Create(h)
+}
+
+func TestRunCopiesAndVerifies(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  func(t *testing.T) *service.Stores
+	}{
+		{"memory to memory", func(*testing.T) *service.Stores { return memoryStores() }},
+		{"memory to sqlite", sqliteStores},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := memoryStores()
+			seed(t, src, 3)
+			counts, err := Run(src, tt.dst(t), filepath.Join(t.TempDir(), "state.json"), t.Logf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if counts.Users != 1 || counts.Habits != 3 || counts.Checkins != 9 {
+				t.Errorf("counts = %+v", counts)
+			}
+		})
+	}
+}
+
+// Прерванный перенос продолжается с сохранённого места
+func TestRunResumes(t *testing.T) {
+	src := memoryStores()
+	seed(t, src, 3)
+	dst := sqliteStores(t)
+	statePath := filepath.Join(t.TempDir(), "state.json")
+
+	broken := *dst
+	broken.Habits = &failingHabits{HabitStore: dst.Habits, limit: 1}
+	if _, err := Run(src, &broken, statePath, t.Logf); err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("first run: err = %v, want disk full", err)
+	}
+	st, err := loadState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Phase != phaseHabits || st.LastKey != "a-habit" {
+		t.Errorf("state = %+v, want habits after a-habit", st)
+	}
+
+	counts, err := Run(src, dst, statePath, t.Logf)
+	if err != nil {
+		t.Fatalf("resumed run: %v", err)
+	}
+	if counts.Habits != 3 || counts.Checkins != 9 {
+		t.Errorf("counts = %+v", counts)
+	}
+	if st, _ := loadState(statePath); st.Phase != phaseDone {
+		t.Errorf("phase = %s, want done", st.Phase)
+	}
+}
+
+// Сверка ловит расхождения между источником и приёмником
+func TestRunChecksumMismatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		state string // содержимое файла состояния перед запуском
+		tweak func(t *testing.T, dst *service.Stores)
+	}{
+		{
+			// в пустой приёмник попадут только отметки c-habit
+			name:  "skipped records",
+			state: `{"phase":"checkins","last_key":"b-habit|2026-10-03"}`,
+		},
+		{
+			name:  "changed record",
+			state: `{"phase":"done"}`,
+			tweak: func(t *testing.T, dst *service.Stores) {
+				h, err := dst.Habits.FindByID("a-habit")
+				if err != nil {
+					t.Fatal(err)
+				}
+				h.Name = "renamed"
+				if err := dst.Habits.Update(h); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := memoryStores()
+			seed(t, src, 3)
+			dst := sqliteStores(t)
+			if tt.tweak != nil {
+				if _, err := Run(src, dst, filepath.Join(t.TempDir(), "state.json"), t.Logf); err != nil {
+					t.Fatal(err)
+				}
+				tt.tweak(t, dst)
+			}
+			// файл состояния говорит, что часть записей уже перенесена
+			statePath := filepath.Join(t.TempDir(), "state.json")
+			if err := os.WriteFile(statePath, []byte(tt.state), 0600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Run(src, dst, statePath, t.Logf)
+			if err == nil || !strings.Contains(err.Error(), "verification failed") {
+				t.Errorf("err = %v, want verification failure", err)
+			}
+		})
+	}
+}